@@ -2,12 +2,16 @@ package commands
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"lloader/internal/app"
+	"lloader/internal/hf"
 )
 
 func NewConfigCommand(cfg *app.Config) *cobra.Command {
@@ -26,12 +30,35 @@ func NewConfigCommand(cfg *app.Config) *cobra.Command {
 			fmt.Println("Current Configuration:")
 			fmt.Println("=====================")
 			fmt.Printf("Config File: %s\n", viper.ConfigFileUsed())
-			fmt.Printf("Models Directory: %s\n", cfg.ModelsDir)
+			fmt.Printf("Models Directories: %s\n", strings.Join(cfg.ModelDirs(), ", "))
+			fmt.Printf("Scan Depth: %d (ignoring %s)\n", cfg.ScanDepth, strings.Join(cfg.ScanIgnore, ", "))
+			fmt.Printf("Index File: %s\n", cfg.IndexFile)
+			fmt.Printf("User Data File: %s\n", cfg.UserDataFile)
+			fmt.Printf("Watch Models: %t (debounce %s)\n", cfg.WatchModels, cfg.WatchDebounce)
+			fmt.Printf("Download Directory: %s (%d at a time)\n", cfg.DownloadTarget(), cfg.DownloadParallel)
+			fmt.Printf("Download Queue File: %s\n", cfg.DownloadStateFile)
+			fmt.Printf("HF Cache: %s (TTL %s)\n", cfg.HFCacheDir, cfg.HFCacheTTL)
+			endpoint, _ := hf.Endpoint(cfg.HFEndpoint)
+			fmt.Printf("HF Endpoint: %s\n", endpoint)
+			if _, source := hf.Token(cfg.HFToken); source != "" {
+				fmt.Printf("HF Token: set (from %s)\n", source)
+			} else {
+				fmt.Println("HF Token: not set")
+			}
 			fmt.Printf("Default NGL: %d\n", cfg.DefaultNGL)
 			fmt.Printf("Log Level: %s\n", cfg.LogLevel)
 			fmt.Printf("Log File: %s\n", cfg.LogFile)
 			fmt.Printf("Server Template: %s\n", cfg.ServerTemplate)
 			fmt.Printf("CLI Template: %s\n", cfg.CLITemplate)
+			fmt.Printf("Server HF Template: %s\n", cfg.ServerHFTemplate)
+			fmt.Printf("CLI HF Template: %s\n", cfg.CLIHFTemplate)
+			for _, name := range slices.Sorted(maps.Keys(cfg.TemplateVars)) {
+				fmt.Printf("Template Var {%s}: %s\n", name, cfg.TemplateVars[name])
+			}
+			for _, profile := range cfg.Profiles {
+				fmt.Printf("Profile %s: %s\n", profile.Label(), strings.Join(profile.Match, ", "))
+			}
+			fmt.Printf("Stop Signal: %s (timeout %s)\n", cfg.StopSignal, cfg.StopTimeout)
 			fmt.Println()
 			fmt.Println("Environment Variables:")
 			fmt.Println("=====================")
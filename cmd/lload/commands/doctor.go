@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"lloader/internal/app"
+	"lloader/internal/hf"
+	"lloader/internal/process"
+)
+
+func NewDoctorCommand(cfg *app.Config) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the HuggingFace endpoint, token and llama.cpp",
+		Long: `Run diagnostics: whether the HuggingFace endpoint (hf_endpoint or
+HF_ENDPOINT) answers API requests, whether it accepts the token, whether the
+download directory is writable and whether the llama.cpp binaries of the
+command templates are on the PATH.
+
+Exits with status 1 if a check failed.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			d := &doctor{}
+
+			endpoint, source := hf.Endpoint(cfg.HFEndpoint)
+			if source == "" {
+				source = "default"
+			}
+			fmt.Printf("HF Endpoint: %s (from %s)\n", endpoint, source)
+			token, tokenSource := hf.Token(cfg.HFToken)
+			client := hf.NewClient(endpoint, token)
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			elapsed, err := client.Ping(ctx)
+			if err != nil {
+				d.fail("Hub", "%v", err)
+			} else {
+				d.ok("Hub", "answered in %s", elapsed.Round(time.Millisecond))
+			}
+
+			switch {
+			case token == "":
+				d.ok("HF Token", "not set, only public repos are available")
+			case err != nil:
+				d.warn("HF Token", "set (from %s), not checked", tokenSource)
+			default:
+				name, err := client.WhoAmI(ctx)
+				hint := hf.AccessHint(err, endpoint, "", true)
+				var status *hf.StatusError
+				switch {
+				case err == nil:
+					d.ok("HF Token", "set (from %s), account %s", tokenSource, name)
+				case hint != "":
+					d.fail("HF Token", "set (from %s): %s", tokenSource, hint)
+				case errors.As(err, &status) && status.Code == 404:
+					d.warn("HF Token", "set (from %s), the endpoint cannot verify tokens", tokenSource)
+				default:
+					d.warn("HF Token", "set (from %s), not checked: %v", tokenSource, err)
+				}
+			}
+
+			dir := cfg.DownloadTarget()
+			if err := checkWritable(dir); err != nil {
+				d.fail("Download Directory", "%v", err)
+			} else {
+				d.ok("Download Directory", "%s is writable", dir)
+			}
+
+			checkBinaries(d, cfg)
+
+			if d.failed {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "how long to wait for the endpoint")
+	return cmd
+}
+
+// doctor prints check results and remembers whether one failed
+type doctor struct {
+	failed bool
+}
+
+func (d *doctor) ok(check, format string, args ...any) {
+	fmt.Printf("[ok]   %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) warn(check, format string, args ...any) {
+	fmt.Printf("[warn] %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) fail(check, format string, args ...any) {
+	d.failed = true
+	fmt.Printf("[FAIL] %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+// checkBinaries looks up the program each command template runs on the
+// PATH. The templates are expanded for a sample model like a launch would,
+// so quoting and optional sections are handled the same way.
+func checkBinaries(d *doctor, cfg *app.Config) {
+	pm := process.NewProcessManager(zap.NewNop())
+	pm.SetTemplates(process.Templates{
+		Server:   cfg.ServerTemplate,
+		CLI:      cfg.CLITemplate,
+		ServerHF: cfg.ServerHFTemplate,
+		CLIHF:    cfg.CLIHFTemplate,
+	})
+	pm.SetTemplateVars(cfg.TemplateVars)
+
+	checked := make(map[string]bool)
+	for _, spec := range []process.LaunchSpec{
+		{Mode: process.ModeServer, ModelPath: "model.gguf", ModelName: "model.gguf"},
+		{Mode: process.ModeCLI, ModelPath: "model.gguf", ModelName: "model.gguf"},
+		{Mode: process.ModeServer, HFRepo: "org/repo", HFQuant: "Q4_K_M"},
+		{Mode: process.ModeCLI, HFRepo: "org/repo", HFQuant: "Q4_K_M"},
+	} {
+		argv, err := pm.Args(spec)
+		if err != nil {
+			check := spec.Mode.String() + " template"
+			if spec.IsHF() {
+				check = spec.Mode.String() + " HF template"
+			}
+			d.fail(check, "%v", err)
+			continue
+		}
+		if checked[argv[0]] {
+			continue
+		}
+		checked[argv[0]] = true
+		if path, err := exec.LookPath(argv[0]); err != nil {
+			d.fail(argv[0], "not found on the PATH")
+		} else {
+			d.ok(argv[0], "%s", path)
+		}
+	}
+}
+
+// checkWritable creates and removes a file in dir, creating dir if needed
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".lload-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
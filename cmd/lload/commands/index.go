@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+	"lloader/internal/models"
+)
+
+func NewIndexCommand(cfg *app.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Manage the model metadata index",
+		Long:  "Manage the on-disk index that caches model metadata between runs",
+	}
+	cmd.AddCommand(newIndexRebuildCommand(cfg))
+	return cmd
+}
+
+func newIndexRebuildCommand(cfg *app.Config) *cobra.Command {
+	var hash bool
+
+	cmd := &cobra.Command{
+		Use:   "rebuild",
+		Short: "Rebuild the model index from scratch",
+		Long: `Discard the model index and rescan every model file.
+
+Launch history is kept. With --hash the SHA-256 of every indexed file is
+computed as well, which reads each file completely.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if cfg.IndexFile == "" {
+				fmt.Fprintln(os.Stderr, "Error: the model index is disabled (index_file is empty)")
+				os.Exit(1)
+			}
+
+			logger, err := app.SetupLogger(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to setup logger: %v\n", err)
+				os.Exit(1)
+			}
+			defer logger.Sync()
+
+			if err := models.ClearIndex(cfg.IndexFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			modelList, err := models.DiscoverModels(cfg, logger)
+			if err != nil {
+				logger.Error("Failed to discover models", zap.Error(err))
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			ix, err := models.OpenIndex(cfg.IndexFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Indexed %d files (%d models) in %s\n", ix.Len(), len(modelList), ix.Path())
+
+			if !hash {
+				return
+			}
+			paths := ix.Unhashed()
+			for i, path := range paths {
+				fmt.Printf("[%d/%d] %s\n", i+1, len(paths), path)
+				if _, err := ix.Hash(path); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to hash %s: %v\n", path, err)
+					continue
+				}
+				// Save as we go so an interrupted run keeps its progress
+				if err := ix.Save(); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&hash, "hash", false, "also compute SHA-256 checksums")
+	return cmd
+}
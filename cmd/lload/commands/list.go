@@ -3,6 +3,8 @@ package commands
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -12,10 +14,15 @@ import (
 )
 
 func NewListCommand(cfg *app.Config) *cobra.Command {
-	return &cobra.Command{
+	var tags []string
+
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List available models",
-		Long:  "List all available llama.cpp models in the configured models directory",
+		Long: `List all available llama.cpp models in the configured models directory.
+
+With --tag only models carrying every given tag are listed. Favorites are
+listed first and marked with a star.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			logger, err := app.SetupLogger(cfg)
 			if err != nil {
@@ -31,18 +38,72 @@ func NewListCommand(cfg *app.Config) *cobra.Command {
 				os.Exit(1)
 			}
 
+			userData, err := app.LoadUserData(cfg.UserDataFile)
+			if err != nil {
+				logger.Warn("Ignoring unreadable user data", zap.String("file", cfg.UserDataFile), zap.Error(err))
+			}
+			wanted := app.ParseTags(strings.Join(tags, ","))
+			modelList = slices.DeleteFunc(modelList, func(model models.Model) bool {
+				return !userData.Model(model.Path).HasTags(wanted)
+			})
+			slices.SortStableFunc(modelList, func(a, b models.Model) int {
+				return app.FavoritesFirst(userData.Model(a.Path), userData.Model(b.Path))
+			})
+
 			if len(modelList) == 0 {
 				fmt.Println("No models found.")
 				return
 			}
 
 			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "NAME\tSIZE\tPATH")
+			fmt.Fprintln(w, "NAME\tSOURCE\tARCH\tPARAMS\tQUANT\tCTX\tLAYERS\tTOKENIZER\tTEMPLATE\tSIZE\tTAGS\tPATH")
 			for _, model := range modelList {
 				sizeMB := float64(model.Size) / (1024 * 1024)
-				fmt.Fprintf(w, "%s\t%.2f MB\t%s\n", model.Name, sizeMB, model.Path)
+				arch, params, quant, ctx, layers, tokenizer, template := "-", "-", "-", "-", "-", "-", "-"
+				if g := model.GGUF; g != nil {
+					arch = orDash(g.Architecture)
+					params = orDash(g.Params())
+					quant = orDash(g.Quantization())
+					tokenizer = orDash(g.TokenizerModel)
+					if g.ContextLength > 0 {
+						ctx = fmt.Sprintf("%d", g.ContextLength)
+					}
+					if g.BlockCount > 0 {
+						layers = fmt.Sprintf("%d", g.BlockCount)
+					}
+					if g.ChatTemplate != "" {
+						template = "yes"
+					}
+				}
+				if quant == "-" && model.HFQuant != "" {
+					quant = model.HFQuant
+				}
+				annotation := userData.Model(model.Path)
+				name := model.Name
+				if annotation.Favorite {
+					name = "★ " + name
+				}
+				if shards := model.ShardInfo(); shards != "" {
+					name += " [" + shards + "]"
+				}
+				if model.Projector != nil {
+					name += " [vision]"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%.2f MB\t%s\t%s\n",
+					name, orDash(string(model.Source)), arch, params, quant, ctx, layers, tokenizer, template, sizeMB,
+					orDash(strings.Join(annotation.Tags, ",")), model.Path)
 			}
 			w.Flush()
 		},
 	}
+
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "only list models with this tag (repeatable)")
+	return cmd
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"lloader/internal/app"
+	"lloader/internal/models"
+	"lloader/internal/process"
+)
+
+func NewRenderCommand(cfg *app.Config) *cobra.Command {
+	var cli, hf bool
+	var ngl, ctxSize int
+
+	cmd := &cobra.Command{
+		Use:   "render-command <model>",
+		Short: "Print the command that would be run for a model",
+		Long: `Expand the server (or CLI) template for a model without starting anything.
+
+The model is looked up in the models directories unless it is a path to an
+existing file. With --hf the model is a HuggingFace repo, optionally
+followed by :quant, and the HF templates are used instead. The exact
+argument vector is printed one argument per line.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			logger, err := app.SetupLogger(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to setup logger: %v\n", err)
+				os.Exit(1)
+			}
+			defer logger.Sync()
+
+			spec := process.LaunchSpec{Mode: process.ModeServer, NGL: ngl, CtxSize: ctxSize}
+			if cli {
+				spec.Mode = process.ModeCLI
+			}
+			if hf {
+				spec.HFRepo, spec.HFQuant, _ = strings.Cut(args[0], ":")
+				spec.ModelName = spec.HFRepo
+			} else {
+				spec.ModelPath, spec.ModelName = resolveModel(cfg, args[0])
+				// Discovery knows about paired projectors
+				if found, err := models.DiscoverModels(cfg, logger); err == nil {
+					for _, model := range found {
+						if model.Path == spec.ModelPath && model.Projector != nil {
+							spec.Projector = model.Projector.Path
+						}
+					}
+				}
+			}
+
+			spec, profile := cfg.LaunchSpec(spec)
+			if profile != nil {
+				fmt.Fprintf(os.Stderr, "Using profile %q\n", profile.Label())
+			}
+			// Explicit flags win over the profile
+			if cmd.Flags().Changed("ngl") {
+				spec.NGL = ngl
+			}
+			if cmd.Flags().Changed("ctx-size") {
+				spec.CtxSize = ctxSize
+			}
+
+			pm := process.NewProcessManager(logger)
+			pm.SetTemplates(process.Templates{
+				Server:   cfg.ServerTemplate,
+				CLI:      cfg.CLITemplate,
+				ServerHF: cfg.ServerHFTemplate,
+				CLIHF:    cfg.CLIHFTemplate,
+			})
+			pm.SetTemplateVars(cfg.TemplateVars)
+
+			argv, err := pm.Args(spec)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Println(process.QuoteArgs(argv))
+			fmt.Println()
+			for i, arg := range argv {
+				fmt.Printf("[%d] %s\n", i, arg)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&cli, "cli", false, "render the CLI template instead of the server template")
+	cmd.Flags().BoolVar(&hf, "hf", false, "treat the model as a HuggingFace repo[:quant]")
+	cmd.Flags().IntVar(&ngl, "ngl", cfg.DefaultNGL, "number of GPU layers")
+	cmd.Flags().IntVar(&ctxSize, "ctx-size", cfg.DefaultCtxSize, "context size (0 = model default)")
+
+	return cmd
+}
+
+// resolveModel finds model as a file path or below one of the models
+// directories, and returns its path and display name
+func resolveModel(cfg *app.Config, model string) (string, string) {
+	if _, err := os.Stat(model); err == nil {
+		return model, filepath.Base(model)
+	}
+	for _, dir := range cfg.ModelDirs() {
+		path := filepath.Join(dir, filepath.FromSlash(model))
+		if _, err := os.Stat(path); err == nil {
+			return path, model
+		}
+	}
+	return filepath.Join(cfg.ModelDirs()[0], filepath.FromSlash(model)), model
+}
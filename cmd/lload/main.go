@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 	"lloader/cmd/lload/commands"
 	"lloader/internal/app"
 	"lloader/internal/models"
@@ -40,6 +41,9 @@ in either server mode or CLI mode.`,
 	rootCmd.AddCommand(
 		commands.NewListCommand(cfg),
 		commands.NewConfigCommand(cfg),
+		commands.NewRenderCommand(cfg),
+		commands.NewIndexCommand(cfg),
+		commands.NewDoctorCommand(cfg),
 		commands.NewVersionCommand(),
 	)
 
@@ -58,15 +62,24 @@ func runTUI(cfg *app.Config) error {
 
 	modelList, err := models.DiscoverModels(cfg, logger)
 	if err != nil {
-		return fmt.Errorf("failed to discover models: %w", err)
+		// The HuggingFace tab works without local models
+		logger.Error("Failed to discover models", zap.Error(err))
 	}
 
 	if len(modelList) == 0 {
-		return fmt.Errorf("no models found in %s", cfg.ModelsDir)
+		logger.Info("No local models found, starting on the HuggingFace tab", zap.Strings("directories", cfg.ModelDirs()))
 	}
 
-	modelNames := models.GetModelNames(modelList)
-	program := ui.NewProgram(modelNames, cfg, logger)
+	program := ui.NewProgram(modelList, cfg, logger)
+	if cfg.WatchModels {
+		watcher, err := models.NewWatcher(cfg, logger, cfg.WatchDebounce)
+		if err != nil {
+			logger.Warn("Not watching models directories", zap.Error(err))
+		} else {
+			defer watcher.Close()
+			program.WatchModels(watcher.Updates())
+		}
+	}
 	if _, err := program.Run(); err != nil {
 		return fmt.Errorf("failed to run TUI: %w", err)
 	}
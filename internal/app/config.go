@@ -4,31 +4,129 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
 type Config struct {
-	ModelsDir      string `mapstructure:"models_dir" yaml:"models_dir"`
+	ModelsDir string `mapstructure:"models_dir" yaml:"models_dir"`
+	// ModelsDirs lists several models directories; ModelsDir is used when
+	// it is empty
+	ModelsDirs []string `mapstructure:"models_dirs" yaml:"models_dirs"`
+	// ScanDepth is how many directory levels below each models directory
+	// are searched, 0 only looks at the top level
+	ScanDepth int `mapstructure:"scan_depth" yaml:"scan_depth"`
+	// ScanIgnore holds globs matched against file and directory names and
+	// against paths relative to the models directory
+	ScanIgnore []string `mapstructure:"scan_ignore" yaml:"scan_ignore"`
+	// ScanLlamaCache and ScanHFCache add the llama.cpp download cache and
+	// the huggingface_hub cache as model sources
+	ScanLlamaCache bool `mapstructure:"scan_llama_cache" yaml:"scan_llama_cache"`
+	ScanHFCache    bool `mapstructure:"scan_hf_cache" yaml:"scan_hf_cache"`
+	// ScanOllama and ScanLMStudio add models from other apps' stores in
+	// place, without copying them
+	ScanOllama   bool `mapstructure:"scan_ollama" yaml:"scan_ollama"`
+	ScanLMStudio bool `mapstructure:"scan_lmstudio" yaml:"scan_lmstudio"`
+	// LMStudioDir overrides the LM Studio models directory
+	LMStudioDir string `mapstructure:"lmstudio_dir" yaml:"lmstudio_dir"`
+	// IndexFile caches model metadata between runs, "" disables it
+	IndexFile string `mapstructure:"index_file" yaml:"index_file"`
+	// UserDataFile stores favorites, tags and notes; it defaults to
+	// userdata.yaml next to the config file
+	UserDataFile string `mapstructure:"user_data_file" yaml:"user_data_file"`
+	// WatchModels refreshes the Local list when model files change; a
+	// rescan waits until nothing changed for WatchDebounce
+	WatchModels   bool          `mapstructure:"watch_models" yaml:"watch_models"`
+	WatchDebounce time.Duration `mapstructure:"watch_debounce" yaml:"watch_debounce"`
+	// DownloadDir receives downloaded models, the first models directory
+	// if empty
+	DownloadDir string `mapstructure:"download_dir" yaml:"download_dir"`
+	// DownloadParallel is how many queued downloads run at once
+	DownloadParallel int `mapstructure:"download_parallel" yaml:"download_parallel"`
+	// DownloadStateFile keeps the download queue across restarts, ""
+	// keeps it in memory
+	DownloadStateFile string `mapstructure:"download_state_file" yaml:"download_state_file"`
+	// HFCacheDir keeps HuggingFace search results and repo details for
+	// HFCacheTTL, and for offline use after that; "" disables it
+	HFCacheDir string        `mapstructure:"hf_cache_dir" yaml:"hf_cache_dir"`
+	HFCacheTTL time.Duration `mapstructure:"hf_cache_ttl" yaml:"hf_cache_ttl"`
+	// HFToken is the HuggingFace access token for private and gated repos;
+	// HF_TOKEN takes precedence and huggingface-cli's token file is the
+	// fallback
+	HFToken string `mapstructure:"hf_token" yaml:"hf_token"`
+	// HFEndpoint is the Hub's base URL, for a self-hosted mirror; "" uses
+	// HF_ENDPOINT or huggingface.co
+	HFEndpoint string `mapstructure:"hf_endpoint" yaml:"hf_endpoint"`
+
 	DefaultNGL     int    `mapstructure:"default_ngl" yaml:"default_ngl"`
 	DefaultCtxSize int    `mapstructure:"default_ctx_size" yaml:"default_ctx_size"`
 	LogLevel       string `mapstructure:"log_level" yaml:"log_level"`
 	LogFile        string `mapstructure:"log_file" yaml:"log_file"`
 	ServerTemplate string `mapstructure:"server_template" yaml:"server_template"`
 	CLITemplate    string `mapstructure:"cli_template" yaml:"cli_template"`
+	// ServerHFTemplate and CLIHFTemplate launch models straight from
+	// HuggingFace; they get {hf_repo}, {hf_quant} and {hf_file}
+	ServerHFTemplate string `mapstructure:"server_hf_template" yaml:"server_hf_template"`
+	CLIHFTemplate    string `mapstructure:"cli_hf_template" yaml:"cli_hf_template"`
+	// TemplateVars are extra {placeholders} for the command templates
+	TemplateVars map[string]string `mapstructure:"template_vars" yaml:"template_vars"`
+	// Profiles are per-model launch settings; the first match wins
+	Profiles []Profile `mapstructure:"profiles" yaml:"profiles"`
+	// CLIPTY runs llama-cli under a pseudo-terminal (Linux only)
+	CLIPTY bool `mapstructure:"cli_pty" yaml:"cli_pty"`
+
+	// Shutdown: StopSignal is sent to the process group first, SIGKILL
+	// follows once StopTimeout has passed
+	StopSignal  string        `mapstructure:"stop_signal" yaml:"stop_signal"`
+	StopTimeout time.Duration `mapstructure:"stop_timeout" yaml:"stop_timeout"`
 }
 
 func DefaultConfig() *Config {
 	return &Config{
-		ModelsDir:      defaultModelsDir(),
-		DefaultNGL:     99,
-		DefaultCtxSize: 0, // 0 lets the model choose
-		LogLevel:       "info",
-		LogFile:        "",
-		ServerTemplate: "llama-server -m {model_path} -ngl {ngl} -c {ctx_size}",
-		CLITemplate:    "llama-cli -m {model_path} -ngl {ngl} -c {ctx_size}",
+		ModelsDir:         defaultModelsDir(),
+		ScanDepth:         5,
+		ScanIgnore:        []string{".*"},
+		ScanLlamaCache:    true,
+		ScanHFCache:       true,
+		ScanOllama:        true,
+		ScanLMStudio:      true,
+		IndexFile:         cacheFile("index.json"),
+		WatchModels:       true,
+		WatchDebounce:     2 * time.Second,
+		DownloadParallel:  2,
+		DownloadStateFile: cacheFile("downloads.json"),
+		HFCacheDir:        cacheFile("hf"),
+		HFCacheTTL:        time.Hour,
+		DefaultNGL:        99,
+		DefaultCtxSize:    0, // 0 lets the model choose
+		LogLevel:          "info",
+		LogFile:           "",
+		ServerTemplate:    "llama-server -m {model_path} [--mmproj {mmproj}] -ngl {ngl} [-c {ctx_size}] [--port {port}]",
+		CLITemplate:       "llama-cli -m {model_path} [--mmproj {mmproj}] -ngl {ngl} [-c {ctx_size}]",
+		ServerHFTemplate:  "llama-server -hf {hf_repo}[:{hf_quant}] [--hf-file {hf_file}] -ngl {ngl} [-c {ctx_size}] [--port {port}]",
+		CLIHFTemplate:     "llama-cli -hf {hf_repo}[:{hf_quant}] [--hf-file {hf_file}] -ngl {ngl} [-c {ctx_size}]",
+		StopSignal:        "SIGTERM",
+		StopTimeout:       10 * time.Second,
+	}
+}
+
+// ModelDirs returns the models directories to scan
+func (c *Config) ModelDirs() []string {
+	if len(c.ModelsDirs) > 0 {
+		return c.ModelsDirs
+	}
+	return []string{c.ModelsDir}
+}
+
+// DownloadTarget returns the directory downloads go to
+func (c *Config) DownloadTarget() string {
+	if c.DownloadDir != "" {
+		return c.DownloadDir
 	}
+	return c.ModelDirs()[0]
 }
 
 func defaultModelsDir() string {
@@ -39,6 +137,29 @@ func defaultModelsDir() string {
 	return filepath.Join(home, "models")
 }
 
+// cacheFile is $XDG_CACHE_HOME/lloader/<name> or the platform's equivalent
+func cacheFile(name string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "lloader", name)
+}
+
+// defaultUserDataFile is userdata.yaml next to the config file in use, or
+// in ~/.config/lloader if there is none or it is the system-wide one
+func defaultUserDataFile(configFile string) string {
+	const name = "userdata.yaml"
+	if configFile != "" && !strings.HasPrefix(configFile, "/etc/") {
+		return filepath.Join(filepath.Dir(configFile), name)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(home, ".config", "lloader", name)
+}
+
 func LoadConfig() (*Config, error) {
 	cfg := DefaultConfig()
 
@@ -49,12 +170,35 @@ func LoadConfig() (*Config, error) {
 	viper.AddConfigPath("/etc/lloader")
 
 	viper.SetDefault("models_dir", cfg.ModelsDir)
+	viper.SetDefault("scan_depth", cfg.ScanDepth)
+	viper.SetDefault("scan_ignore", cfg.ScanIgnore)
+	viper.SetDefault("scan_llama_cache", cfg.ScanLlamaCache)
+	viper.SetDefault("scan_hf_cache", cfg.ScanHFCache)
+	viper.SetDefault("scan_ollama", cfg.ScanOllama)
+	viper.SetDefault("scan_lmstudio", cfg.ScanLMStudio)
+	viper.SetDefault("lmstudio_dir", cfg.LMStudioDir)
+	viper.SetDefault("index_file", cfg.IndexFile)
+	viper.SetDefault("user_data_file", cfg.UserDataFile)
+	viper.SetDefault("watch_models", cfg.WatchModels)
+	viper.SetDefault("watch_debounce", cfg.WatchDebounce)
+	viper.SetDefault("download_dir", cfg.DownloadDir)
+	viper.SetDefault("download_parallel", cfg.DownloadParallel)
+	viper.SetDefault("download_state_file", cfg.DownloadStateFile)
+	viper.SetDefault("hf_cache_dir", cfg.HFCacheDir)
+	viper.SetDefault("hf_cache_ttl", cfg.HFCacheTTL)
+	viper.SetDefault("hf_token", cfg.HFToken)
+	viper.SetDefault("hf_endpoint", cfg.HFEndpoint)
 	viper.SetDefault("default_ngl", cfg.DefaultNGL)
 	viper.SetDefault("default_ctx_size", cfg.DefaultCtxSize)
 	viper.SetDefault("log_level", cfg.LogLevel)
 	viper.SetDefault("log_file", cfg.LogFile)
 	viper.SetDefault("server_template", cfg.ServerTemplate)
 	viper.SetDefault("cli_template", cfg.CLITemplate)
+	viper.SetDefault("server_hf_template", cfg.ServerHFTemplate)
+	viper.SetDefault("cli_hf_template", cfg.CLIHFTemplate)
+	viper.SetDefault("cli_pty", cfg.CLIPTY)
+	viper.SetDefault("stop_signal", cfg.StopSignal)
+	viper.SetDefault("stop_timeout", cfg.StopTimeout)
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -66,6 +210,16 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if cfg.UserDataFile == "" {
+		cfg.UserDataFile = defaultUserDataFile(viper.ConfigFileUsed())
+	}
+
+	for i := range cfg.Profiles {
+		if err := cfg.Profiles[i].validate(); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", cfg.Profiles[i].Label(), err)
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"lloader/internal/process"
+)
+
+// Profile holds launch settings for the models it matches. Unset fields
+// leave the defaults alone.
+type Profile struct {
+	Name string `mapstructure:"name" yaml:"name"`
+	// Match lists globs tested case-insensitively against the model's file
+	// name, its path relative to the models directory, or its HF repo ID
+	Match []string `mapstructure:"match" yaml:"match"`
+	// Args is appended to the command and may use template placeholders
+	Args    string   `mapstructure:"args" yaml:"args"`
+	Env     []string `mapstructure:"env" yaml:"env"`
+	NGL     *int     `mapstructure:"ngl" yaml:"ngl"`
+	CtxSize *int     `mapstructure:"ctx_size" yaml:"ctx_size"`
+	Port    int      `mapstructure:"port" yaml:"port"`
+}
+
+// Label returns the profile name, or its first pattern for unnamed profiles
+func (p *Profile) Label() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	if len(p.Match) > 0 {
+		return p.Match[0]
+	}
+	return "unnamed"
+}
+
+// Matches reports whether any of the profile's globs matches one of names
+func (p *Profile) Matches(names ...string) bool {
+	for _, pattern := range p.Match {
+		pattern = strings.ToLower(pattern)
+		for _, name := range names {
+			if name == "" {
+				continue
+			}
+			if ok, _ := path.Match(pattern, strings.ToLower(name)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply copies the profile's settings into spec
+func (p *Profile) Apply(spec *process.LaunchSpec) {
+	if p.NGL != nil {
+		spec.NGL = *p.NGL
+	}
+	if p.CtxSize != nil {
+		spec.CtxSize = *p.CtxSize
+	}
+	if p.Port != 0 {
+		spec.Port = p.Port
+	}
+	spec.ExtraArgs = p.Args
+	spec.Env = append(slices.Clip(spec.Env), p.Env...)
+}
+
+func (p *Profile) validate() error {
+	if len(p.Match) == 0 {
+		return fmt.Errorf("no match patterns")
+	}
+	for _, pattern := range p.Match {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid match pattern %q: %w", pattern, err)
+		}
+	}
+	for _, kv := range p.Env {
+		if key, _, ok := strings.Cut(kv, "="); !ok || key == "" {
+			return fmt.Errorf("env entry %q is not KEY=VALUE", kv)
+		}
+	}
+	if p.Port < 0 || p.Port > 65535 {
+		return fmt.Errorf("invalid port %d", p.Port)
+	}
+	return nil
+}
+
+// ProfileFor returns the first profile matching one of names, or nil
+func (c *Config) ProfileFor(names ...string) *Profile {
+	for i := range c.Profiles {
+		if c.Profiles[i].Matches(names...) {
+			return &c.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// LaunchSpec returns spec with the matching profile applied, together with
+// that profile. Local models are matched by their name and base name, HF
+// models by their repo ID.
+func (c *Config) LaunchSpec(spec process.LaunchSpec) (process.LaunchSpec, *Profile) {
+	var profile *Profile
+	if spec.IsHF() {
+		profile = c.ProfileFor(spec.HFRepo)
+	} else {
+		profile = c.ProfileFor(spec.ModelName, path.Base(spec.ModelName))
+	}
+	if profile != nil {
+		profile.Apply(&spec)
+	}
+	return spec, profile
+}
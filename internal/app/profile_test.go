@@ -0,0 +1,126 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"lloader/internal/process"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestConfig_LaunchSpec(t *testing.T) {
+	cfg := &Config{Profiles: []Profile{
+		{Name: "qwen", Match: []string{"qwen*.gguf"}, Args: "--jinja -fa", NGL: intPtr(40), Port: 8081},
+		{Name: "vision", Match: []string{"*/gemma-3-*", "unsloth/gemma-3-*"}, CtxSize: intPtr(8192), Env: []string{"CUDA_VISIBLE_DEVICES=1"}},
+		{Name: "catch-all", Match: []string{"qwen3-*"}, Args: "--never"},
+	}}
+
+	tests := []struct {
+		name        string
+		spec        process.LaunchSpec
+		wantProfile string
+		want        process.LaunchSpec
+	}{
+		{
+			name:        "matches file name case-insensitively",
+			spec:        process.LaunchSpec{ModelName: "Qwen3-8B-Q4_K_M.gguf", NGL: 99},
+			wantProfile: "qwen",
+			want:        process.LaunchSpec{ModelName: "Qwen3-8B-Q4_K_M.gguf", NGL: 40, Port: 8081, ExtraArgs: "--jinja -fa"},
+		},
+		{
+			name:        "matches base name of a nested model",
+			spec:        process.LaunchSpec{ModelName: "vendor/qwen/qwen3-8b.gguf", NGL: 99},
+			wantProfile: "qwen",
+			want:        process.LaunchSpec{ModelName: "vendor/qwen/qwen3-8b.gguf", NGL: 40, Port: 8081, ExtraArgs: "--jinja -fa"},
+		},
+		{
+			name:        "matches relative path",
+			spec:        process.LaunchSpec{ModelName: "google/gemma-3-4b.gguf", NGL: 99},
+			wantProfile: "vision",
+			want:        process.LaunchSpec{ModelName: "google/gemma-3-4b.gguf", NGL: 99, CtxSize: 8192, Env: []string{"CUDA_VISIBLE_DEVICES=1"}},
+		},
+		{
+			name:        "matches HF repo",
+			spec:        process.LaunchSpec{HFRepo: "unsloth/gemma-3-4b-it-GGUF", HFQuant: "Q4_K_M", NGL: 99},
+			wantProfile: "vision",
+			want:        process.LaunchSpec{HFRepo: "unsloth/gemma-3-4b-it-GGUF", HFQuant: "Q4_K_M", NGL: 99, CtxSize: 8192, Env: []string{"CUDA_VISIBLE_DEVICES=1"}},
+		},
+		{
+			name: "no match",
+			spec: process.LaunchSpec{ModelName: "llama-3.gguf", NGL: 99},
+			want: process.LaunchSpec{ModelName: "llama-3.gguf", NGL: 99},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, profile := cfg.LaunchSpec(tt.spec)
+			if tt.wantProfile == "" {
+				assert.Nil(t, profile)
+			} else {
+				require.NotNil(t, profile)
+				assert.Equal(t, tt.wantProfile, profile.Label())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProfile_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		wantErr string
+	}{
+		{name: "valid", profile: Profile{Match: []string{"*.gguf"}, Env: []string{"A=1", "B="}}},
+		{name: "no patterns", profile: Profile{Name: "x"}, wantErr: "no match patterns"},
+		{name: "bad pattern", profile: Profile{Match: []string{"[a"}}, wantErr: "invalid match pattern"},
+		{name: "bad env", profile: Profile{Match: []string{"*"}, Env: []string{"NOVALUE"}}, wantErr: "not KEY=VALUE"},
+		{name: "bad port", profile: Profile{Match: []string{"*"}, Port: 70000}, wantErr: "invalid port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.profile.validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProfile_Decode(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+profiles:
+  - name: qwen
+    match: "Qwen*"
+    args: "--jinja --temp 0.7"
+    ngl: 0
+    env: ["CUDA_VISIBLE_DEVICES=1"]
+  - match: ["a*", "b*"]
+    ctx_size: 4096
+`)))
+
+	var cfg Config
+	require.NoError(t, v.Unmarshal(&cfg))
+	require.Len(t, cfg.Profiles, 2)
+
+	assert.Equal(t, []string{"Qwen*"}, cfg.Profiles[0].Match)
+	require.NotNil(t, cfg.Profiles[0].NGL)
+	assert.Equal(t, 0, *cfg.Profiles[0].NGL)
+	assert.Nil(t, cfg.Profiles[0].CtxSize)
+	assert.Equal(t, []string{"CUDA_VISIBLE_DEVICES=1"}, cfg.Profiles[0].Env)
+
+	assert.Equal(t, "a*", cfg.Profiles[1].Label())
+	assert.Nil(t, cfg.Profiles[1].NGL)
+	require.NotNil(t, cfg.Profiles[1].CtxSize)
+	assert.Equal(t, 4096, *cfg.Profiles[1].CtxSize)
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserData holds what the user noted about models: favorites, tags and
+// notes for local model files, keyed by absolute path, and for
+// HuggingFace repos, keyed by repo ID. It also keeps the last-used
+// HuggingFace search filters. It is kept in a YAML file next to the config
+// so it can also be edited by hand.
+type UserData struct {
+	path string
+	// readErr is why the file could not be loaded. Save does not
+	// overwrite such a file, so a typo made by hand does not lose it.
+	readErr error
+
+	Models   map[string]*Annotation `yaml:"models,omitempty"`
+	Repos    map[string]*Annotation `yaml:"repos,omitempty"`
+	HFSearch SearchFilters          `yaml:"hf_search,omitempty"`
+}
+
+// SearchFilters are the HuggingFace search options besides the query
+type SearchFilters struct {
+	Sort        string `yaml:"sort,omitempty"`
+	Author      string `yaml:"author,omitempty"`
+	PipelineTag string `yaml:"pipeline_tag,omitempty"`
+	License     string `yaml:"license,omitempty"`
+}
+
+// Annotation is what the user noted about one model or repo
+type Annotation struct {
+	Favorite bool     `yaml:"favorite,omitempty"`
+	Tags     []string `yaml:"tags,omitempty"`
+	Note     string   `yaml:"note,omitempty"`
+}
+
+// LoadUserData reads the user data file at path. A missing file gives
+// empty user data that Save creates. A file that cannot be read or parsed
+// gives empty user data along with the error, and is left alone by Save.
+func LoadUserData(path string) (*UserData, error) {
+	u := &UserData{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return u, nil
+	}
+	if err != nil {
+		return &UserData{path: path, readErr: err}, err
+	}
+	if err := yaml.Unmarshal(data, u); err != nil {
+		return &UserData{path: path, readErr: err}, err
+	}
+	return u, nil
+}
+
+// ReadOnly reports whether the file could not be loaded, in which case
+// Save refuses to overwrite it
+func (u *UserData) ReadOnly() bool {
+	return u.readErr != nil
+}
+
+// Path returns the file the user data is stored in
+func (u *UserData) Path() string {
+	return u.path
+}
+
+// Save writes the user data, dropping empty annotations
+func (u *UserData) Save() error {
+	if u.readErr != nil {
+		return fmt.Errorf("not overwriting %s, it could not be loaded: %w", u.path, u.readErr)
+	}
+	for _, m := range []map[string]*Annotation{u.Models, u.Repos} {
+		for key, a := range m {
+			if a == nil || a.IsZero() {
+				delete(m, key)
+			}
+		}
+	}
+
+	data, err := yaml.Marshal(u)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(u.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".userdata-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), u.path)
+}
+
+// Model returns the annotation of the model file at path
+func (u *UserData) Model(path string) Annotation {
+	if a := u.Models[modelKey(path)]; a != nil {
+		return *a
+	}
+	return Annotation{}
+}
+
+// Repo returns the annotation of a HuggingFace repo
+func (u *UserData) Repo(id string) Annotation {
+	if a := u.Repos[id]; a != nil {
+		return *a
+	}
+	return Annotation{}
+}
+
+// SetModel replaces the annotation of the model file at path
+func (u *UserData) SetModel(path string, a Annotation) {
+	if u.Models == nil {
+		u.Models = make(map[string]*Annotation)
+	}
+	u.Models[modelKey(path)] = &a
+}
+
+// SetRepo replaces the annotation of a HuggingFace repo
+func (u *UserData) SetRepo(id string, a Annotation) {
+	if u.Repos == nil {
+		u.Repos = make(map[string]*Annotation)
+	}
+	u.Repos[id] = &a
+}
+
+// ReposWithTags returns the annotated repos that carry all tags, sorted
+func (u *UserData) ReposWithTags(tags []string) []string {
+	var ids []string
+	for id, a := range u.Repos {
+		if a != nil && a.HasTags(tags) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func modelKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// IsZero reports whether nothing is noted
+func (a Annotation) IsZero() bool {
+	return !a.Favorite && len(a.Tags) == 0 && a.Note == ""
+}
+
+// HasTags reports whether a carries every one of tags, ignoring case
+func (a Annotation) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(a.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// FavoritesFirst orders favorites before other annotations, for use with
+// a stable sort
+func FavoritesFirst(a, b Annotation) int {
+	switch {
+	case a.Favorite && !b.Favorite:
+		return -1
+	case !a.Favorite && b.Favorite:
+		return 1
+	}
+	return 0
+}
+
+// ParseTags splits a comma- or space-separated tag list. Tags are
+// lowercased, a leading "#" is dropped and duplicates are removed.
+func ParseTags(s string) []string {
+	var tags []string
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		tag := strings.ToLower(strings.TrimPrefix(field, "#"))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// SplitTagQuery separates "#tag" words from the rest of a filter or
+// search query
+func SplitTagQuery(query string) (text string, tags []string) {
+	var words []string
+	for _, word := range strings.Fields(query) {
+		if tag, ok := strings.CutPrefix(word, "#"); ok {
+			if tag != "" {
+				tags = append(tags, strings.ToLower(tag))
+			}
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), tags
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserData_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lloader", "userdata.yaml")
+
+	u, err := LoadUserData(path)
+	require.NoError(t, err)
+	assert.Equal(t, Annotation{}, u.Model("/models/a.gguf"))
+
+	u.SetModel("/models/a.gguf", Annotation{Favorite: true, Tags: []string{"coding"}, Note: "fast"})
+	u.SetModel("/models/b.gguf", Annotation{})
+	u.SetRepo("Qwen/Qwen3-8B-GGUF", Annotation{Tags: []string{"coding", "eval-baseline"}})
+	require.NoError(t, u.Save())
+
+	loaded, err := LoadUserData(path)
+	require.NoError(t, err)
+	assert.Equal(t, Annotation{Favorite: true, Tags: []string{"coding"}, Note: "fast"}, loaded.Model("/models/a.gguf"))
+	assert.Equal(t, []string{"coding", "eval-baseline"}, loaded.Repo("Qwen/Qwen3-8B-GGUF").Tags)
+	assert.NotContains(t, loaded.Models, "/models/b.gguf", "empty annotations are dropped")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "favorite: true")
+	assert.NotContains(t, string(data), "hf_search", "unset filters are left out")
+
+	loaded.HFSearch = SearchFilters{Sort: "likes", Author: "unsloth"}
+	require.NoError(t, loaded.Save())
+	loaded, err = LoadUserData(path)
+	require.NoError(t, err)
+	assert.Equal(t, SearchFilters{Sort: "likes", Author: "unsloth"}, loaded.HFSearch)
+}
+
+func TestUserData_RelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	u, err := LoadUserData(filepath.Join(dir, "userdata.yaml"))
+	require.NoError(t, err)
+	u.SetModel("models/a.gguf", Annotation{Favorite: true})
+	assert.True(t, u.Model(filepath.Join(dir, "models", "a.gguf")).Favorite)
+	assert.True(t, u.Model("./models/../models/a.gguf").Favorite)
+}
+
+func TestLoadUserData_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "userdata.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("models: [not, a, map]"), 0644))
+
+	u, err := LoadUserData(path)
+	assert.Error(t, err)
+	require.NotNil(t, u)
+	assert.Empty(t, u.Models)
+	assert.True(t, u.ReadOnly())
+}
+
+func TestUserData_SaveKeepsInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "userdata.yaml")
+	original := []byte("models:\n  /m/a.gguf: {favorite: true\n")
+	require.NoError(t, os.WriteFile(path, original, 0644))
+
+	u, err := LoadUserData(path)
+	require.Error(t, err)
+
+	u.SetModel("/m/b.gguf", Annotation{Favorite: true})
+	assert.ErrorContains(t, u.Save(), "not overwriting")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, data)
+}
+
+func TestUserData_ReposWithTags(t *testing.T) {
+	u := &UserData{}
+	u.SetRepo("b/vision", Annotation{Tags: []string{"vision", "coding"}})
+	u.SetRepo("a/coder", Annotation{Tags: []string{"coding"}})
+	u.SetRepo("c/none", Annotation{Favorite: true})
+
+	assert.Equal(t, []string{"a/coder", "b/vision"}, u.ReposWithTags([]string{"coding"}))
+	assert.Equal(t, []string{"b/vision"}, u.ReposWithTags([]string{"CODING", "vision"}))
+	assert.Empty(t, u.ReposWithTags([]string{"missing"}))
+}
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"coding", []string{"coding"}},
+		{"Coding, vision,eval-baseline", []string{"coding", "vision", "eval-baseline"}},
+		{"#coding #vision coding", []string{"coding", "vision"}},
+		{" , ,", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ParseTags(tt.in), tt.in)
+	}
+}
+
+func TestSplitTagQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		wantText string
+		wantTags []string
+	}{
+		{"qwen q4", "qwen q4", nil},
+		{"#coding", "", []string{"coding"}},
+		{"qwen #Coding  #vision q4", "qwen q4", []string{"coding", "vision"}},
+		{"# lonely", "lonely", nil},
+	}
+	for _, tt := range tests {
+		text, tags := SplitTagQuery(tt.query)
+		assert.Equal(t, tt.wantText, text, tt.query)
+		assert.Equal(t, tt.wantTags, tags, tt.query)
+	}
+}
+
+func TestDefaultUserDataFile(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	assert.Equal(t, "/srv/lloader/userdata.yaml", defaultUserDataFile("/srv/lloader/config.yaml"))
+	assert.Equal(t, filepath.Join(home, ".config", "lloader", "userdata.yaml"), defaultUserDataFile("/etc/lloader/config.yaml"))
+	assert.Equal(t, filepath.Join(home, ".config", "lloader", "userdata.yaml"), defaultUserDataFile(""))
+}
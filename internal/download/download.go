@@ -0,0 +1,419 @@
+// Package download fetches GGUF files from the HuggingFace Hub into the
+// models directory. Transfers resume from partial files with HTTP range
+// requests and are checked against the sha256 the Hub reports for LFS
+// files.
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	hfmodels "github.com/Megatherium/hf-go"
+	"lloader/internal/hf"
+)
+
+// partSuffix marks files that are still being downloaded
+const partSuffix = ".part"
+
+// progressInterval limits how often progress is reported
+const progressInterval = 100 * time.Millisecond
+
+// ErrChecksum is returned when a downloaded file does not match the
+// sha256 published for it
+var ErrChecksum = errors.New("checksum mismatch")
+
+// RepoInfo lists the files of a repo; *hfmodels.Client implements it
+type RepoInfo interface {
+	GetModelDetails(id string) (*hfmodels.ModelDetails, error)
+}
+
+// Downloader fetches files of HuggingFace repos
+type Downloader struct {
+	Repos RepoInfo
+	HTTP  *http.Client
+	// Endpoint is the Hub's base URL, hf.DefaultEndpoint if empty
+	Endpoint string
+	// Token is sent as a bearer token if set
+	Token string
+	// Revision is the branch, tag or commit to download, "main" if empty
+	Revision string
+}
+
+// New returns a Downloader for the public Hub
+func New(repos RepoInfo) *Downloader {
+	return &Downloader{Repos: repos, HTTP: http.DefaultClient}
+}
+
+// File is one file of a download
+type File struct {
+	// Name is the path within the repo
+	Name string
+	// Size is the length in bytes, 0 if the Hub did not say
+	Size int64
+	// SHA256 is the checksum of LFS files, "" for others
+	SHA256 string
+}
+
+// Progress reports how far a download got
+type Progress struct {
+	Repo string
+	// File is the file being transferred, FileIndex its 0-based position
+	File      string
+	FileIndex int
+	FileCount int
+	// Done and Total count bytes over all files; Total is 0 if unknown
+	Done  int64
+	Total int64
+	// Verifying is set while the checksum of a finished file is checked
+	Verifying bool
+}
+
+// Fraction returns how much is done, between 0 and 1
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(1, float64(p.Done)/float64(p.Total))
+}
+
+// Plan returns the files of quant in repo, with their sizes and
+// checksums. Split models give one file per shard, in order.
+func (d *Downloader) Plan(ctx context.Context, repo, quant string) ([]File, error) {
+	details, err := d.Repos.GetModelDetails(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of %s: %w", repo, err)
+	}
+	var names []string
+	for _, sibling := range details.Siblings {
+		names = append(names, sibling.RFilename)
+	}
+	names = QuantFiles(names, quant)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no %s GGUF files in %s", quant, repo)
+	}
+
+	files := make([]File, len(names))
+	for i, name := range names {
+		if files[i], err = d.stat(ctx, repo, name); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Download fetches the files of quant in repo to dir/<repo>/, resuming
+// partial files left by earlier attempts, and returns the paths of the
+// finished files. progress, if set, is called from the calling goroutine.
+func (d *Downloader) Download(ctx context.Context, repo, quant, dir string, progress func(Progress)) ([]string, error) {
+	files, err := d.Plan(ctx, repo, quant)
+	if err != nil {
+		return nil, err
+	}
+	return d.Fetch(ctx, repo, files, dir, progress)
+}
+
+// Fetch downloads planned files of repo to dir/<repo>/, one after the
+// other, like Download
+func (d *Downloader) Fetch(ctx context.Context, repo string, files []File, dir string, progress func(Progress)) ([]string, error) {
+	p := Progress{Repo: repo, FileCount: len(files)}
+	for _, f := range files {
+		p.Total += f.Size
+	}
+	report := func(force bool) {}
+	if progress != nil {
+		var last time.Time
+		report = func(force bool) {
+			if now := time.Now(); force || now.Sub(last) >= progressInterval {
+				last = now
+				progress(p)
+			}
+		}
+	}
+
+	var paths []string
+	for i, f := range files {
+		p.File, p.FileIndex = f.Name, i
+		dest := Dest(dir, repo, f.Name)
+		base := p.Done
+		err := d.fetch(ctx, repo, f, dest, func(n int64, verifying bool) {
+			p.Done, p.Verifying = base+n, verifying
+			report(verifying)
+		})
+		if err != nil {
+			return paths, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		p.Done = base + f.Size
+		paths = append(paths, dest)
+	}
+	p.Verifying = false
+	report(true)
+	return paths, nil
+}
+
+// Dest is where a file of repo is stored below dir
+func Dest(dir, repo, name string) string {
+	return filepath.Join(dir, filepath.FromSlash(repo), filepath.FromSlash(name))
+}
+
+// RemovePartial deletes what an unfinished download of a file left behind
+func RemovePartial(dir, repo, name string) error {
+	err := os.Remove(Dest(dir, repo, name) + partSuffix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// fetch downloads one file to dest via dest.part. A complete dest is
+// kept as it is.
+func (d *Downloader) fetch(ctx context.Context, repo string, f File, dest string, progress func(n int64, verifying bool)) error {
+	if info, err := os.Stat(dest); err == nil && (f.Size == 0 || info.Size() == f.Size) {
+		progress(info.Size(), false)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	part := dest + partSuffix
+	out, err := os.OpenFile(part, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	// Hash what an earlier attempt left, then continue after it
+	h := sha256.New()
+	offset, err := io.Copy(h, out)
+	if err != nil {
+		return err
+	}
+	if f.Size > 0 && offset > f.Size {
+		if offset, err = restart(out, h); err != nil {
+			return err
+		}
+	}
+	progress(offset, false)
+
+	if f.Size == 0 || offset < f.Size {
+		req, err := d.request(ctx, http.MethodGet, repo, f.Name)
+		if err != nil {
+			return err
+		}
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+		resp, err := d.HTTP.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		complete := false
+		switch {
+		case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+			// Nothing after the offset: without a size to compare with,
+			// this is how a part an earlier attempt finished shows up
+			complete = true
+		case resp.StatusCode == http.StatusOK:
+			// The server ignored the range, start over
+			if offset, err = restart(out, h); err != nil {
+				return err
+			}
+		default:
+			return statusError(resp)
+		}
+
+		w := io.MultiWriter(out, h)
+		buf := make([]byte, 256<<10)
+		for !complete {
+			n, err := resp.Body.Read(buf)
+			if n > 0 {
+				if _, err := w.Write(buf[:n]); err != nil {
+					return err
+				}
+				offset += int64(n)
+				progress(offset, false)
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if f.Size > 0 && offset != f.Size {
+		return fmt.Errorf("got %d of %d bytes", offset, f.Size)
+	}
+	progress(offset, true)
+	if f.SHA256 != "" {
+		if sum := hex.EncodeToString(h.Sum(nil)); sum != f.SHA256 {
+			out.Close()
+			os.Remove(part)
+			return fmt.Errorf("%w: got %s, want %s", ErrChecksum, sum, f.SHA256)
+		}
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(part, dest)
+}
+
+// restart empties a partial file and its running hash
+func restart(f *os.File, h hash.Hash) (int64, error) {
+	h.Reset()
+	if err := f.Truncate(0); err != nil {
+		return 0, err
+	}
+	_, err := f.Seek(0, io.SeekStart)
+	return 0, err
+}
+
+// stat asks the Hub for the size and checksum of a file. LFS files carry
+// them in X-Linked-Size and X-Linked-Etag on the redirect to the CDN,
+// which is therefore not followed.
+func (d *Downloader) stat(ctx context.Context, repo, name string) (File, error) {
+	req, err := d.request(ctx, http.MethodHead, repo, name)
+	if err != nil {
+		return File{}, err
+	}
+	client := *d.HTTP
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		return File{}, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return File{}, fmt.Errorf("%s: %w", name, statusError(resp))
+	}
+
+	f := File{Name: name}
+	size := resp.Header.Get("X-Linked-Size")
+	if size == "" && resp.StatusCode < 300 {
+		size = resp.Header.Get("Content-Length")
+	}
+	f.Size, _ = strconv.ParseInt(size, 10, 64)
+
+	etag := resp.Header.Get("X-Linked-Etag")
+	if etag == "" {
+		etag = resp.Header.Get("ETag")
+	}
+	if etag = strings.Trim(strings.TrimPrefix(etag, "W/"), `"`); sha256Pattern.MatchString(etag) {
+		f.SHA256 = etag
+	}
+	return f, nil
+}
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func (d *Downloader) request(ctx context.Context, method, repo, name string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, d.fileURL(repo, name), nil)
+	if err != nil {
+		return nil, err
+	}
+	if d.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+d.Token)
+	}
+	return req, nil
+}
+
+// fileURL is the Hub's resolve URL of a file
+func (d *Downloader) fileURL(repo, name string) string {
+	endpoint := strings.TrimSuffix(d.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = hf.DefaultEndpoint
+	}
+	revision := d.Revision
+	if revision == "" {
+		revision = "main"
+	}
+	return endpoint + "/" + repo + "/resolve/" + url.PathEscape(revision) + "/" + escapePath(name)
+}
+
+func escapePath(name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// StatusError is an unexpected HTTP response from the Hub
+type StatusError struct {
+	Code   int
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return "unexpected response " + e.Status
+}
+
+// StatusCode returns the HTTP status code
+func (e *StatusError) StatusCode() int {
+	return e.Code
+}
+
+func statusError(resp *http.Response) error {
+	return &StatusError{Code: resp.StatusCode, Status: resp.Status}
+}
+
+// QuantFiles picks the GGUF files of one quantization out of a repo's file
+// list, e.g. every shard of Q4_K_M whether it is named
+// "Model-Q4_K_M.gguf" or "Q4_K_M/Model-Q4_K_M-00001-of-00002.gguf".
+// Projectors are left out.
+func QuantFiles(names []string, quant string) []string {
+	var files []string
+	for _, name := range names {
+		base := path.Base(name)
+		if !strings.EqualFold(path.Ext(base), ".gguf") || strings.Contains(strings.ToLower(base), "mmproj") {
+			continue
+		}
+		if hasToken(name, quant) {
+			files = append(files, name)
+		}
+	}
+	slices.Sort(files)
+	return files
+}
+
+// hasToken reports whether quant appears in name between separators, so
+// Q4_K does not match Q4_K_M
+func hasToken(name, quant string) bool {
+	name, quant = strings.ToUpper(name), strings.ToUpper(quant)
+	if quant == "" {
+		return false
+	}
+	for i := 0; ; {
+		j := strings.Index(name[i:], quant)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(quant)
+		before := start == 0 || strings.IndexByte("-._/", name[start-1]) >= 0
+		after := end == len(name) || strings.IndexByte("-.", name[end]) >= 0
+		if before && after {
+			return true
+		}
+		i = start + 1
+	}
+}
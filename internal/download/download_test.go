@@ -0,0 +1,307 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	hfmodels "github.com/Megatherium/hf-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHub serves files like the Hub's resolve endpoint: HEAD answers with
+// a redirect carrying X-Linked-Size and X-Linked-Etag, GET honors ranges
+type fakeHub struct {
+	files map[string][]byte
+	// etags overrides the checksum reported for a file
+	etags map[string]string
+	// noRange makes GET ignore Range headers
+	noRange bool
+	// noSize leaves the size out of HEAD responses
+	noSize bool
+	// hold, if set, stalls GETs after the first half of the file until it
+	// is closed or the request is canceled
+	hold chan struct{}
+
+	mu     sync.Mutex
+	ranges []string
+	auth   string
+}
+
+func (h *fakeHub) GetModelDetails(id string) (*hfmodels.ModelDetails, error) {
+	d := &hfmodels.ModelDetails{ID: id}
+	for name := range h.files {
+		d.Siblings = append(d.Siblings, hfmodels.Sibling{RFilename: name})
+	}
+	d.Siblings = append(d.Siblings, hfmodels.Sibling{RFilename: "README.md"})
+	return d, nil
+}
+
+func (h *fakeHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutPrefix(r.URL.Path, "/org/repo/resolve/main/")
+	data, found := h.files[name]
+	if !ok || !found {
+		http.NotFound(w, r)
+		return
+	}
+	h.mu.Lock()
+	h.ranges = append(h.ranges, r.Header.Get("Range"))
+	h.auth = r.Header.Get("Authorization")
+	h.mu.Unlock()
+
+	if r.Method == http.MethodHead {
+		sum := sha256.Sum256(data)
+		etag := hex.EncodeToString(sum[:])
+		if e, ok := h.etags[name]; ok {
+			etag = e
+		}
+		if !h.noSize {
+			w.Header().Set("X-Linked-Size", strconv.Itoa(len(data)))
+		}
+		w.Header().Set("X-Linked-Etag", `"`+etag+`"`)
+		w.Header().Set("Location", "/cdn/"+name)
+		w.WriteHeader(http.StatusFound)
+		return
+	}
+
+	var offset int
+	if rng := r.Header.Get("Range"); rng != "" && !h.noRange {
+		fmt.Sscanf(rng, "bytes=%d-", &offset)
+		if offset >= len(data) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(data)-1, len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+	}
+	if h.hold != nil {
+		half := max(offset, len(data)/2)
+		w.Write(data[offset:half])
+		w.(http.Flusher).Flush()
+		select {
+		case <-h.hold:
+		case <-r.Context().Done():
+			return
+		}
+		offset = half
+	}
+	w.Write(data[offset:])
+}
+
+func newTestDownloader(t *testing.T, hub *fakeHub) *Downloader {
+	srv := httptest.NewServer(hub)
+	t.Cleanup(srv.Close)
+	d := New(hub)
+	d.Endpoint = srv.URL
+	return d
+}
+
+func TestDownload(t *testing.T) {
+	hub := &fakeHub{files: map[string][]byte{
+		"Model-Q4_K_M.gguf": []byte(strings.Repeat("q4km", 1000)),
+		"Model-Q8_0.gguf":   []byte("q8"),
+	}}
+	d := newTestDownloader(t, hub)
+	d.Token = "hf_secret"
+	dir := t.TempDir()
+
+	var last Progress
+	paths, err := d.Download(context.Background(), "org/repo", "q4_k_m", dir, func(p Progress) { last = p })
+	require.NoError(t, err)
+
+	want := filepath.Join(dir, "org", "repo", "Model-Q4_K_M.gguf")
+	assert.Equal(t, []string{want}, paths)
+	data, err := os.ReadFile(want)
+	require.NoError(t, err)
+	assert.Equal(t, hub.files["Model-Q4_K_M.gguf"], data)
+	assert.NoFileExists(t, want+partSuffix)
+
+	assert.Equal(t, int64(4000), last.Total)
+	assert.Equal(t, int64(4000), last.Done)
+	assert.Equal(t, 1.0, last.Fraction())
+	assert.Equal(t, "Bearer hf_secret", hub.auth)
+}
+
+func TestDownload_Resume(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+	hub := &fakeHub{files: map[string][]byte{"Model-Q8_0.gguf": content}}
+	d := newTestDownloader(t, hub)
+	dir := t.TempDir()
+
+	dest := filepath.Join(dir, "org", "repo", "Model-Q8_0.gguf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(dest), 0755))
+	require.NoError(t, os.WriteFile(dest+partSuffix, content[:300], 0644))
+
+	_, err := d.Download(context.Background(), "org/repo", "Q8_0", dir, nil)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Contains(t, hub.ranges, "bytes=300-")
+}
+
+func TestDownload_ResumeCompleteWithoutSize(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+	hub := &fakeHub{files: map[string][]byte{"Model-Q8_0.gguf": content}, noSize: true}
+	d := newTestDownloader(t, hub)
+	dir := t.TempDir()
+
+	// An earlier run got every byte but stopped before the rename
+	dest := filepath.Join(dir, "org", "repo", "Model-Q8_0.gguf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(dest), 0755))
+	require.NoError(t, os.WriteFile(dest+partSuffix, content, 0644))
+
+	_, err := d.Download(context.Background(), "org/repo", "Q8_0", dir, nil)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Contains(t, hub.ranges, "bytes=1000-")
+	assert.NoFileExists(t, dest+partSuffix)
+}
+
+func TestDownload_ServerIgnoresRange(t *testing.T) {
+	content := []byte(strings.Repeat("abcdef", 50))
+	hub := &fakeHub{files: map[string][]byte{"Model-Q8_0.gguf": content}, noRange: true}
+	d := newTestDownloader(t, hub)
+	dir := t.TempDir()
+
+	dest := filepath.Join(dir, "org", "repo", "Model-Q8_0.gguf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(dest), 0755))
+	require.NoError(t, os.WriteFile(dest+partSuffix, content[:100], 0644))
+
+	_, err := d.Download(context.Background(), "org/repo", "Q8_0", dir, nil)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestDownload_ChecksumMismatch(t *testing.T) {
+	hub := &fakeHub{
+		files: map[string][]byte{"Model-Q8_0.gguf": []byte("corrupted")},
+		etags: map[string]string{"Model-Q8_0.gguf": strings.Repeat("ab", 32)},
+	}
+	d := newTestDownloader(t, hub)
+	dir := t.TempDir()
+
+	_, err := d.Download(context.Background(), "org/repo", "Q8_0", dir, nil)
+	assert.True(t, errors.Is(err, ErrChecksum), "got %v", err)
+
+	dest := filepath.Join(dir, "org", "repo", "Model-Q8_0.gguf")
+	assert.NoFileExists(t, dest)
+	assert.NoFileExists(t, dest+partSuffix, "a corrupt part is not resumed")
+}
+
+func TestDownload_SplitShards(t *testing.T) {
+	hub := &fakeHub{files: map[string][]byte{
+		"Q4_K_M/Model-Q4_K_M-00002-of-00002.gguf": []byte("second"),
+		"Q4_K_M/Model-Q4_K_M-00001-of-00002.gguf": []byte("first"),
+		"Model-Q4_K_S.gguf":                       []byte("other"),
+	}}
+	d := newTestDownloader(t, hub)
+	dir := t.TempDir()
+
+	var seen []string
+	paths, err := d.Download(context.Background(), "org/repo", "Q4_K_M", dir, func(p Progress) {
+		if len(seen) == 0 || seen[len(seen)-1] != p.File {
+			seen = append(seen, p.File)
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "org", "repo", "Q4_K_M", "Model-Q4_K_M-00001-of-00002.gguf"),
+		filepath.Join(dir, "org", "repo", "Q4_K_M", "Model-Q4_K_M-00002-of-00002.gguf"),
+	}, paths)
+	assert.Equal(t, "Q4_K_M/Model-Q4_K_M-00001-of-00002.gguf", seen[0])
+}
+
+func TestDownload_Canceled(t *testing.T) {
+	hub := &fakeHub{files: map[string][]byte{"Model-Q8_0.gguf": []byte("data")}}
+	d := newTestDownloader(t, hub)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := d.Download(ctx, "org/repo", "Q8_0", t.TempDir(), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDownload_UnknownQuant(t *testing.T) {
+	hub := &fakeHub{files: map[string][]byte{"Model-Q8_0.gguf": []byte("data")}}
+	d := newTestDownloader(t, hub)
+
+	_, err := d.Download(context.Background(), "org/repo", "IQ2_XXS", t.TempDir(), nil)
+	assert.ErrorContains(t, err, "no IQ2_XXS GGUF files")
+}
+
+func TestQuantFiles(t *testing.T) {
+	names := []string{
+		"README.md",
+		"Model-Q4_K.gguf",
+		"Model-Q4_K_M.gguf",
+		"Model-IQ4_XS.gguf",
+		"model.q4_k_m.gguf",
+		"mmproj-Model-Q4_K_M.gguf",
+		"Q8_0/Model-Q8_0-00001-of-00003.gguf",
+		"Q8_0/Model-Q8_0-00003-of-00003.gguf",
+		"Q8_0/Model-Q8_0-00002-of-00003.gguf",
+	}
+
+	tests := []struct {
+		quant string
+		want  []string
+	}{
+		{"Q4_K_M", []string{"Model-Q4_K_M.gguf", "model.q4_k_m.gguf"}},
+		{"Q4_K", []string{"Model-Q4_K.gguf"}},
+		{"Q4_XS", nil},
+		{"IQ4_XS", []string{"Model-IQ4_XS.gguf"}},
+		{"Q8_0", []string{
+			"Q8_0/Model-Q8_0-00001-of-00003.gguf",
+			"Q8_0/Model-Q8_0-00002-of-00003.gguf",
+			"Q8_0/Model-Q8_0-00003-of-00003.gguf",
+		}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.quant, func(t *testing.T) {
+			assert.Equal(t, tt.want, QuantFiles(names, tt.quant))
+		})
+	}
+}
+
+func TestDownload_Unauthorized(t *testing.T) {
+	hub := &fakeHub{files: map[string][]byte{"Model-Q8_0.gguf": []byte("data")}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hf_secret" {
+			http.Error(w, "gated", http.StatusUnauthorized)
+			return
+		}
+		hub.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	d := New(hub)
+	d.Endpoint = srv.URL
+
+	_, err := d.Download(context.Background(), "org/repo", "Q8_0", t.TempDir(), nil)
+	var status *StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusUnauthorized, status.StatusCode())
+
+	d.Token = "hf_secret"
+	_, err = d.Download(context.Background(), "org/repo", "Q8_0", t.TempDir(), nil)
+	assert.NoError(t, err)
+}
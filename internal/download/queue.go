@@ -0,0 +1,414 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// queueVersion is bumped when the state file format changes
+const queueVersion = 1
+
+// State is where an item is in the queue
+type State string
+
+const (
+	StateQueued      State = "queued"
+	StateDownloading State = "downloading"
+	StatePaused      State = "paused"
+	StateDone        State = "done"
+	StateFailed      State = "failed"
+	StateCanceled    State = "canceled"
+)
+
+// Finished reports whether the item will not download without being
+// resumed
+func (s State) Finished() bool {
+	return s == StateDone || s == StateFailed || s == StateCanceled
+}
+
+// Item is one quant of a repo in the queue
+type Item struct {
+	Repo  string `json:"repo"`
+	Quant string `json:"quant"`
+	State State  `json:"state"`
+	// Files are the repo files of the quant, known once planned
+	Files []string `json:"files,omitempty"`
+	// Paths are the downloaded files once done
+	Paths []string  `json:"paths,omitempty"`
+	Done  int64     `json:"done,omitempty"`
+	Total int64     `json:"total,omitempty"`
+	Err   string    `json:"error,omitempty"`
+	Added time.Time `json:"added"`
+
+	// Speed is the recent transfer rate in bytes per second
+	Speed float64 `json:"-"`
+	// FileIndex is the file being transferred, of len(Files)
+	FileIndex int `json:"-"`
+
+	sampleAt   time.Time
+	sampleDone int64
+}
+
+// ID identifies an item; a quant of a repo is queued at most once
+func (it Item) ID() string {
+	return it.Repo + ":" + it.Quant
+}
+
+// ETA estimates the time left from the current speed, 0 if unknown
+func (it Item) ETA() time.Duration {
+	if it.Speed <= 0 || it.Total <= it.Done {
+		return 0
+	}
+	return time.Duration(float64(it.Total-it.Done) / it.Speed * float64(time.Second))
+}
+
+// Fraction returns how much of the item is done, between 0 and 1
+func (it Item) Fraction() float64 {
+	return Progress{Done: it.Done, Total: it.Total}.Fraction()
+}
+
+// Queue downloads items in order, at most parallel at a time, and keeps
+// its state in a file so downloads continue after a restart
+type Queue struct {
+	d        *Downloader
+	dir      string
+	path     string
+	parallel int
+
+	mu      sync.Mutex
+	items   []*Item
+	cancels map[string]context.CancelFunc
+	started bool
+	closed  bool
+	wg      sync.WaitGroup
+	updates chan struct{}
+}
+
+type queueFile struct {
+	Version int     `json:"version"`
+	Items   []*Item `json:"items"`
+}
+
+// OpenQueue loads the queue state from path, "" keeps it in memory only.
+// Files go to dir/<repo>/. Items that were downloading when lloader quit
+// are queued again. A corrupt state file gives an empty queue and an
+// error; saving replaces the file.
+func OpenQueue(d *Downloader, dir, path string, parallel int) (*Queue, error) {
+	q := &Queue{
+		d:        d,
+		dir:      dir,
+		path:     path,
+		parallel: max(1, parallel),
+		cancels:  make(map[string]context.CancelFunc),
+		updates:  make(chan struct{}, 1),
+	}
+	if path == "" {
+		return q, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return q, err
+	}
+	var f queueFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return q, fmt.Errorf("failed to read download queue %s: %w", path, err)
+	}
+	if f.Version != queueVersion {
+		return q, nil
+	}
+	for _, it := range f.Items {
+		if it.State == StateDownloading {
+			it.State = StateQueued
+		}
+		q.items = append(q.items, it)
+	}
+	return q, nil
+}
+
+// Dir returns the directory downloads go to
+func (q *Queue) Dir() string {
+	return q.dir
+}
+
+// Updates signals that items changed; read Items for the new state
+func (q *Queue) Updates() <-chan struct{} {
+	return q.updates
+}
+
+// Start begins downloading queued items
+func (q *Queue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.started = true
+	q.schedule()
+}
+
+// Close stops the running transfers, keeping them queued for next time,
+// and waits for them to end
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	q.closed = true
+	for _, cancel := range q.cancels {
+		cancel()
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.save()
+}
+
+// Items returns a snapshot of the queue in order
+func (q *Queue) Items() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := make([]Item, len(q.items))
+	for i, it := range q.items {
+		items[i] = *it
+	}
+	return items
+}
+
+// Add queues a quant of a repo. A finished item for it is queued again;
+// false means it is already waiting or downloading.
+func (q *Queue) Add(repo, quant string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id := Item{Repo: repo, Quant: quant}.ID()
+	if it := q.find(id); it != nil {
+		if !it.State.Finished() && it.State != StatePaused {
+			return false
+		}
+		it.State, it.Err = StateQueued, ""
+	} else {
+		q.items = append(q.items, &Item{Repo: repo, Quant: quant, State: StateQueued, Added: time.Now()})
+	}
+	q.changed()
+	return true
+}
+
+// Pause stops an item, keeping what was downloaded
+func (q *Queue) Pause(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	it := q.find(id)
+	if it == nil || (it.State != StateQueued && it.State != StateDownloading) {
+		return
+	}
+	it.State = StatePaused
+	if cancel := q.cancels[id]; cancel != nil {
+		cancel()
+	}
+	q.changed()
+}
+
+// Resume queues a paused, failed or canceled item again
+func (q *Queue) Resume(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	it := q.find(id)
+	if it == nil || (it.State != StatePaused && it.State != StateFailed && it.State != StateCanceled) {
+		return
+	}
+	it.State, it.Err = StateQueued, ""
+	q.changed()
+}
+
+// Cancel stops an item and deletes its partial files. Finished files are
+// kept.
+func (q *Queue) Cancel(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	it := q.find(id)
+	if it == nil || it.State.Finished() {
+		return
+	}
+	it.State = StateCanceled
+	it.Speed = 0
+	if cancel := q.cancels[id]; cancel != nil {
+		// The transfer removes its files when it has stopped
+		cancel()
+	} else {
+		q.removePartial(it)
+	}
+	q.changed()
+}
+
+// Move shifts an item delta places up (negative) or down the queue;
+// earlier items are downloaded first
+func (q *Queue) Move(id string, delta int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := slices.IndexFunc(q.items, func(it *Item) bool { return it.ID() == id })
+	if i < 0 {
+		return
+	}
+	j := max(0, min(len(q.items)-1, i+delta))
+	if i == j {
+		return
+	}
+	it := q.items[i]
+	q.items = slices.Insert(slices.Delete(q.items, i, i+1), j, it)
+	q.changed()
+}
+
+// ClearFinished drops done, failed and canceled items from the list
+func (q *Queue) ClearFinished() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = slices.DeleteFunc(q.items, func(it *Item) bool { return it.State.Finished() })
+	q.changed()
+}
+
+func (q *Queue) find(id string) *Item {
+	for _, it := range q.items {
+		if it.ID() == id {
+			return it
+		}
+	}
+	return nil
+}
+
+// changed saves the queue, starts what may start and tells the UI.
+// q.mu must be held.
+func (q *Queue) changed() {
+	q.schedule()
+	q.save()
+	q.notify()
+}
+
+// schedule starts queued items in order while fewer than parallel run
+func (q *Queue) schedule() {
+	if !q.started || q.closed {
+		return
+	}
+	for _, it := range q.items {
+		if len(q.cancels) >= q.parallel {
+			return
+		}
+		if _, running := q.cancels[it.ID()]; running || it.State != StateQueued {
+			// A paused transfer that was resumed starts once it stopped
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		q.cancels[it.ID()] = cancel
+		it.State = StateDownloading
+		it.sampleAt = time.Time{}
+		q.wg.Add(1)
+		go q.run(ctx, it)
+	}
+}
+
+// run downloads one item
+func (q *Queue) run(ctx context.Context, it *Item) {
+	defer q.wg.Done()
+
+	files, err := q.d.Plan(ctx, it.Repo, it.Quant)
+	var paths []string
+	if err == nil {
+		q.mu.Lock()
+		// A fresh slice, snapshots from Items share the old one
+		it.Files, it.Total = nil, 0
+		for _, f := range files {
+			it.Files = append(it.Files, f.Name)
+			it.Total += f.Size
+		}
+		q.save()
+		q.mu.Unlock()
+		paths, err = q.d.Fetch(ctx, it.Repo, files, q.dir, func(p Progress) { q.progress(it, p) })
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.cancels, it.ID())
+	it.Speed = 0
+	switch it.State {
+	case StateCanceled:
+		q.removePartial(it)
+	case StateDownloading:
+		switch {
+		case q.closed:
+			// Left downloading so the next start picks it up
+		case err != nil:
+			it.State, it.Err = StateFailed, err.Error()
+		default:
+			it.State, it.Paths, it.Done = StateDone, paths, it.Total
+		}
+	}
+	q.changed()
+}
+
+// progress records a progress report and the transfer rate
+func (q *Queue) progress(it *Item, p Progress) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	if dt := now.Sub(it.sampleAt).Seconds(); !it.sampleAt.IsZero() && dt > 0 {
+		rate := float64(p.Done-it.sampleDone) / dt
+		if it.Speed == 0 {
+			it.Speed = rate
+		} else {
+			it.Speed = 0.8*it.Speed + 0.2*rate
+		}
+	}
+	it.sampleAt, it.sampleDone = now, p.Done
+	it.Done, it.Total, it.FileIndex = p.Done, p.Total, p.FileIndex
+	q.notify()
+}
+
+func (q *Queue) removePartial(it *Item) {
+	for _, name := range it.Files {
+		RemovePartial(q.dir, it.Repo, name)
+	}
+}
+
+// notify wakes the reader of Updates without blocking; one pending
+// signal covers any number of changes
+func (q *Queue) notify() {
+	select {
+	case q.updates <- struct{}{}:
+	default:
+	}
+}
+
+// save writes the state file atomically. q.mu must be held.
+func (q *Queue) save() error {
+	if q.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(queueFile{Version: queueVersion, Items: q.items}, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(q.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".downloads-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), q.path)
+}
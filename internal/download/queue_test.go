@@ -0,0 +1,212 @@
+package download
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitItems waits until the queue's items satisfy cond
+func waitItems(t *testing.T, q *Queue, cond func([]Item) bool) []Item {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		if items := q.Items(); cond(items) {
+			return items
+		}
+		select {
+		case <-q.Updates():
+		case <-time.After(50 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("timed out, queue is %+v", q.Items())
+		}
+	}
+}
+
+func states(items []Item) []State {
+	s := make([]State, len(items))
+	for i, it := range items {
+		s[i] = it.State
+	}
+	return s
+}
+
+func hasStates(want ...State) func([]Item) bool {
+	return func(items []Item) bool {
+		return assert.ObjectsAreEqual(want, states(items))
+	}
+}
+
+// stalled waits until the first item has written the first half of name,
+// where fakeHub.hold stops it
+func stalled(t *testing.T, q *Queue, name string) []Item {
+	part := Dest(q.Dir(), "org/repo", name) + partSuffix
+	return waitItems(t, q, func([]Item) bool {
+		info, err := os.Stat(part)
+		return err == nil && info.Size() > 0
+	})
+}
+
+func queueHub() *fakeHub {
+	return &fakeHub{files: map[string][]byte{
+		"Model-Q4_K_M.gguf": []byte(strings.Repeat("q4km", 500)),
+		"Model-Q8_0.gguf":   []byte(strings.Repeat("q8", 500)),
+	}}
+}
+
+func TestQueue_DownloadsAndPersists(t *testing.T) {
+	hub := queueHub()
+	d := newTestDownloader(t, hub)
+	dir, state := t.TempDir(), filepath.Join(t.TempDir(), "downloads.json")
+
+	q, err := OpenQueue(d, dir, state, 2)
+	require.NoError(t, err)
+	assert.True(t, q.Add("org/repo", "Q4_K_M"))
+	assert.True(t, q.Add("org/repo", "Q8_0"))
+	assert.False(t, q.Add("org/repo", "Q8_0"), "already queued")
+	q.Start()
+
+	items := waitItems(t, q, hasStates(StateDone, StateDone))
+	assert.Equal(t, []string{filepath.Join(dir, "org", "repo", "Model-Q4_K_M.gguf")}, items[0].Paths)
+	assert.Equal(t, int64(2000), items[0].Done)
+	assert.FileExists(t, filepath.Join(dir, "org", "repo", "Model-Q8_0.gguf"))
+	require.NoError(t, q.Close())
+
+	reopened, err := OpenQueue(d, dir, state, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []State{StateDone, StateDone}, states(reopened.Items()))
+	assert.Equal(t, "org/repo:Q8_0", reopened.Items()[1].ID())
+
+	reopened.ClearFinished()
+	assert.Empty(t, reopened.Items())
+}
+
+func TestQueue_Parallel(t *testing.T) {
+	hub := queueHub()
+	hub.hold = make(chan struct{})
+	d := newTestDownloader(t, hub)
+
+	q, err := OpenQueue(d, t.TempDir(), "", 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { q.Close() })
+	q.Add("org/repo", "Q4_K_M")
+	q.Add("org/repo", "Q8_0")
+	q.Start()
+
+	items := stalled(t, q, "Model-Q4_K_M.gguf")
+	assert.Equal(t, []State{StateDownloading, StateQueued}, states(items))
+
+	close(hub.hold)
+	waitItems(t, q, hasStates(StateDone, StateDone))
+}
+
+func TestQueue_PauseResume(t *testing.T) {
+	hub := queueHub()
+	hub.hold = make(chan struct{})
+	d := newTestDownloader(t, hub)
+	dir := t.TempDir()
+
+	q, err := OpenQueue(d, dir, "", 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { q.Close() })
+	q.Add("org/repo", "Q8_0")
+	q.Start()
+	stalled(t, q, "Model-Q8_0.gguf")
+
+	q.Pause("org/repo:Q8_0")
+	waitItems(t, q, func(items []Item) bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return items[0].State == StatePaused && len(q.cancels) == 0
+	})
+	part := filepath.Join(dir, "org", "repo", "Model-Q8_0.gguf") + partSuffix
+	assert.FileExists(t, part, "pausing keeps the partial file")
+
+	close(hub.hold)
+	q.Resume("org/repo:Q8_0")
+	waitItems(t, q, hasStates(StateDone))
+	assert.True(t, slices.ContainsFunc(hub.ranges, func(r string) bool { return strings.HasPrefix(r, "bytes=") }),
+		"resumed with a range request, got %q", hub.ranges)
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	hub := queueHub()
+	hub.hold = make(chan struct{})
+	t.Cleanup(func() { close(hub.hold) })
+	d := newTestDownloader(t, hub)
+	dir := t.TempDir()
+
+	q, err := OpenQueue(d, dir, "", 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { q.Close() })
+	q.Add("org/repo", "Q8_0")
+	q.Add("org/repo", "Q4_K_M")
+	q.Start()
+	stalled(t, q, "Model-Q8_0.gguf")
+
+	q.Cancel("org/repo:Q8_0")
+	q.Cancel("org/repo:Q4_K_M")
+	waitItems(t, q, func(items []Item) bool {
+		_, err := os.Stat(filepath.Join(dir, "org", "repo", "Model-Q8_0.gguf") + partSuffix)
+		return assert.ObjectsAreEqual([]State{StateCanceled, StateCanceled}, states(items)) && os.IsNotExist(err)
+	})
+}
+
+func TestQueue_Move(t *testing.T) {
+	q, err := OpenQueue(New(nil), t.TempDir(), "", 1)
+	require.NoError(t, err)
+	q.Add("a/a", "Q4_K_M")
+	q.Add("b/b", "Q4_K_M")
+	q.Add("c/c", "Q4_K_M")
+
+	ids := func() []string {
+		var ids []string
+		for _, it := range q.Items() {
+			ids = append(ids, it.Repo)
+		}
+		return ids
+	}
+	q.Move("c/c:Q4_K_M", -1)
+	assert.Equal(t, []string{"a/a", "c/c", "b/b"}, ids())
+	q.Move("a/a:Q4_K_M", 5)
+	assert.Equal(t, []string{"c/c", "b/b", "a/a"}, ids())
+	q.Move("c/c:Q4_K_M", -1)
+	assert.Equal(t, []string{"c/c", "b/b", "a/a"}, ids())
+}
+
+func TestOpenQueue_RequeuesInterrupted(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "downloads.json")
+	data, err := json.Marshal(queueFile{Version: queueVersion, Items: []*Item{
+		{Repo: "a/a", Quant: "Q8_0", State: StateDownloading},
+		{Repo: "b/b", Quant: "Q8_0", State: StatePaused},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(state, data, 0644))
+
+	q, err := OpenQueue(New(nil), t.TempDir(), state, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []State{StateQueued, StatePaused}, states(q.Items()))
+}
+
+func TestOpenQueue_Corrupt(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "downloads.json")
+	require.NoError(t, os.WriteFile(state, []byte("{"), 0644))
+
+	q, err := OpenQueue(New(nil), t.TempDir(), state, 1)
+	assert.Error(t, err)
+	require.NotNil(t, q)
+	assert.Empty(t, q.Items())
+}
+
+func TestItem_ETA(t *testing.T) {
+	it := Item{Done: 100, Total: 1100, Speed: 100}
+	assert.Equal(t, 10*time.Second, it.ETA())
+	assert.Zero(t, Item{Done: 100, Total: 1100}.ETA())
+}
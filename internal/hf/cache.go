@@ -0,0 +1,167 @@
+// Package hf talks to the HuggingFace Hub for the TUI, through hf-go or
+// the Hub's REST API: responses are cached on disk so the HuggingFace tab
+// keeps working offline. Searches always use the REST API rather than
+// hf-go's ListModels, which has no cursor to load the next page with.
+package hf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	hfmodels "github.com/Megatherium/hf-go"
+)
+
+// cacheVersion is bumped when cached responses change shape; entries of
+// other versions are ignored
+const cacheVersion = 1
+
+// API is what lloader asks the Hub; NewAPI returns one for an endpoint
+type API interface {
+	// SearchPage returns the first page of a search if next is "", else
+	// the page at next, which a previous page returned
+	SearchPage(opts SearchOptions, next string) (Page, error)
+	GetAvailableQuants(id string) ([]string, error)
+	GetModelDetails(id string) (*hfmodels.ModelDetails, error)
+}
+
+// Info tells how current a response is
+type Info struct {
+	// FetchedAt is when the response came from the Hub
+	FetchedAt time.Time
+	// Cached is set when the response was read from disk
+	Cached bool
+	// Stale is set when the Hub could not be reached and an expired
+	// response was used instead; Err is why the Hub failed
+	Stale bool
+	Err   error
+}
+
+// Cache answers hf-go calls from disk while the responses are younger than
+// the TTL, and from expired responses when the Hub is unreachable
+type Cache struct {
+	api API
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewCache caches responses of api in dir for ttl. With dir "" every call
+// goes to the Hub.
+func NewCache(api API, dir string, ttl time.Duration) *Cache {
+	return &Cache{api: api, dir: dir, ttl: ttl, now: time.Now}
+}
+
+// SearchPage returns the first page of a search if next is "", else the
+// page at next. Pages are cached by the search and their cursor.
+func (c *Cache) SearchPage(opts SearchOptions, next string) (Page, Info, error) {
+	key, err := json.Marshal(opts)
+	if err != nil {
+		return Page{}, Info{}, err
+	}
+	return get(c, "search:"+string(key)+"@"+next, func() (Page, error) {
+		return c.api.SearchPage(opts, next)
+	})
+}
+
+// GetAvailableQuants lists the GGUF quantizations of a repo
+func (c *Cache) GetAvailableQuants(id string) ([]string, Info, error) {
+	return get(c, "quants:"+id, func() ([]string, error) {
+		return c.api.GetAvailableQuants(id)
+	})
+}
+
+// GetModelDetails returns the details of a repo
+func (c *Cache) GetModelDetails(id string) (*hfmodels.ModelDetails, Info, error) {
+	return get(c, "details:"+id, func() (*hfmodels.ModelDetails, error) {
+		return c.api.GetModelDetails(id)
+	})
+}
+
+type cacheEntry struct {
+	Version   int             `json:"version"`
+	Key       string          `json:"key"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// get returns the cached response for key if it is fresh, else fetches it.
+// If fetching fails for other reasons than access, an expired response is
+// returned marked stale.
+func get[T any](c *Cache, key string, fetch func() (T, error)) (T, Info, error) {
+	entry, cached := c.load(key)
+	var value T
+	if cached {
+		if err := json.Unmarshal(entry.Data, &value); err != nil {
+			cached = false
+		}
+	}
+	if cached && c.now().Sub(entry.FetchedAt) < c.ttl {
+		return value, Info{FetchedAt: entry.FetchedAt, Cached: true}, nil
+	}
+
+	fresh, err := fetch()
+	if err != nil {
+		// Access errors are shown as such rather than hidden behind old data
+		if cached && AccessHint(err, "", "", false) == "" {
+			return value, Info{FetchedAt: entry.FetchedAt, Cached: true, Stale: true, Err: err}, nil
+		}
+		return fresh, Info{}, err
+	}
+	now := c.now()
+	c.store(key, now, fresh)
+	return fresh, Info{FetchedAt: now}, nil
+}
+
+// file is where the response for key is kept
+func (c *Cache) file(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+func (c *Cache) load(key string) (cacheEntry, bool) {
+	var entry cacheEntry
+	if c.dir == "" {
+		return entry, false
+	}
+	data, err := os.ReadFile(c.file(key))
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != cacheVersion || entry.Key != key {
+		return entry, false
+	}
+	return entry, true
+}
+
+// store writes a response. The cache is best effort: a response that
+// cannot be written is simply fetched again next time.
+func (c *Cache) store(key string, fetchedAt time.Time, value any) {
+	if c.dir == "" {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	data, err = json.Marshal(cacheEntry{Version: cacheVersion, Key: key, FetchedAt: fetchedAt, Data: data})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.dir, ".entry-*.json")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+	os.Rename(tmp.Name(), c.file(key))
+}
@@ -0,0 +1,150 @@
+package hf
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	hfmodels "github.com/Megatherium/hf-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hubAPI is a minimal client for the stand-in Hub below
+type hubAPI struct {
+	base string
+}
+
+func (a hubAPI) getJSON(path string, v any) error {
+	resp, err := http.Get(a.base + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (a hubAPI) SearchPage(opts SearchOptions, next string) (Page, error) {
+	var page Page
+	err := a.getJSON("/api/models?search="+url.QueryEscape(opts.Search), &page.Models)
+	return page, err
+}
+
+func (a hubAPI) GetAvailableQuants(id string) ([]string, error) {
+	var quants []string
+	err := a.getJSON("/api/quants/"+id, &quants)
+	return quants, err
+}
+
+func (a hubAPI) GetModelDetails(id string) (*hfmodels.ModelDetails, error) {
+	var details hfmodels.ModelDetails
+	err := a.getJSON("/api/models/"+id, &details)
+	return &details, err
+}
+
+// standInHub serves a fixed search result, quant list and repo and counts
+// the requests it got
+func standInHub(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode([]hfmodels.Model{{ID: "org/" + r.URL.Query().Get("search")}})
+	})
+	mux.HandleFunc("/api/quants/", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode([]string{"Q4_K_M", "Q8_0"})
+	})
+	mux.HandleFunc("/api/models/", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode(hfmodels.ModelDetails{ID: "org/repo", Likes: 7})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestCache_TTL(t *testing.T) {
+	srv, requests := standInHub(t)
+	c := NewCache(hubAPI{srv.URL}, t.TempDir(), time.Hour)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	opts := SearchOptions{Search: "qwen", Limit: 20}
+	page, info, err := c.SearchPage(opts, "")
+	require.NoError(t, err)
+	assert.Equal(t, []hfmodels.Model{{ID: "org/qwen"}}, page.Models)
+	assert.False(t, info.Cached)
+	assert.Equal(t, int32(1), requests.Load())
+
+	now = now.Add(30 * time.Minute)
+	page, info, err = c.SearchPage(opts, "")
+	require.NoError(t, err)
+	assert.Equal(t, []hfmodels.Model{{ID: "org/qwen"}}, page.Models)
+	assert.True(t, info.Cached)
+	assert.False(t, info.Stale)
+	assert.Equal(t, int32(1), requests.Load(), "answered from the cache")
+
+	other := opts
+	other.Search = "gemma"
+	page, _, err = c.SearchPage(other, "")
+	require.NoError(t, err)
+	assert.Equal(t, []hfmodels.Model{{ID: "org/gemma"}}, page.Models)
+	assert.Equal(t, int32(2), requests.Load(), "other options are another entry")
+
+	now = now.Add(time.Hour)
+	_, info, err = c.SearchPage(opts, "")
+	require.NoError(t, err)
+	assert.False(t, info.Cached, "expired entries are fetched again")
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestCache_Offline(t *testing.T) {
+	srv, _ := standInHub(t)
+	c := NewCache(hubAPI{srv.URL}, t.TempDir(), time.Minute)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	_, _, err := c.GetAvailableQuants("org/repo")
+	require.NoError(t, err)
+	_, _, err = c.GetModelDetails("org/repo")
+	require.NoError(t, err)
+
+	srv.Close()
+	now = now.Add(3 * time.Hour)
+
+	quants, info, err := c.GetAvailableQuants("org/repo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Q4_K_M", "Q8_0"}, quants)
+	assert.True(t, info.Stale)
+	assert.Error(t, info.Err)
+	assert.Equal(t, now.Add(-3*time.Hour), info.FetchedAt)
+
+	details, info, err := c.GetModelDetails("org/repo")
+	require.NoError(t, err)
+	assert.Equal(t, 7, details.Likes)
+	assert.True(t, info.Stale)
+
+	_, _, err = c.GetModelDetails("org/uncached")
+	assert.Error(t, err, "nothing to fall back to")
+}
+
+func TestCache_Disabled(t *testing.T) {
+	srv, requests := standInHub(t)
+	c := NewCache(hubAPI{srv.URL}, "", time.Hour)
+
+	for range 2 {
+		_, info, err := c.GetAvailableQuants("org/repo")
+		require.NoError(t, err)
+		assert.False(t, info.Cached)
+	}
+	assert.Equal(t, int32(2), requests.Load())
+}
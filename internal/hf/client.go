@@ -0,0 +1,217 @@
+package hf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	hfmodels "github.com/Megatherium/hf-go"
+)
+
+// requestTimeout bounds each request of a Client
+const requestTimeout = 30 * time.Second
+
+// Client talks to the Hub's REST API. It serves searches for every
+// endpoint and everything else for endpoints other than huggingface.co,
+// such as a self-hosted mirror or a local stand-in.
+type Client struct {
+	Endpoint string
+	Token    string
+	HTTP     *http.Client
+}
+
+// NewClient returns a Client for the Hub at endpoint, authenticating with
+// token if it is set
+func NewClient(endpoint, token string) *Client {
+	return &Client{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		Token:    token,
+		HTTP:     &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// SearchPage returns the first page of a search if next is "", else the
+// page at next. The Hub pages with a cursor it links in the Link header;
+// the link must stay on the client's endpoint, as the token is sent along.
+func (c *Client) SearchPage(opts SearchOptions, next string) (Page, error) {
+	u := next
+	if u == "" {
+		query := listQuery(opts.ListModelsOptions())
+		if opts.PipelineTag != "" {
+			query.Set("pipeline_tag", opts.PipelineTag)
+		}
+		if opts.License != "" {
+			query.Add("filter", "license:"+opts.License)
+		}
+		u = c.Endpoint + "/api/models?" + query.Encode()
+	} else if !c.onEndpoint(next) {
+		return Page{}, fmt.Errorf("next page %s is not on %s", next, c.Endpoint)
+	}
+
+	var page Page
+	header, err := c.get(context.Background(), u, &page.Models)
+	if err != nil {
+		return Page{}, err
+	}
+	if link := nextLink(header.Get("Link")); link != "" {
+		// The link may be relative to the page it was sent with
+		base, _ := url.Parse(u)
+		if ref, err := base.Parse(link); err == nil {
+			page.Next = ref.String()
+		}
+	}
+	return page, nil
+}
+
+// onEndpoint reports whether u has the scheme and host of the endpoint
+func (c *Client) onEndpoint(u string) bool {
+	ref, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	endpoint, err := url.Parse(c.Endpoint)
+	return err == nil && ref.Scheme == endpoint.Scheme && ref.Host == endpoint.Host
+}
+
+// nextLink returns the target of the rel="next" link in a Link header,
+// "" if there is none
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+		if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if !strings.EqualFold(name, "rel") {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+				if strings.EqualFold(rel, "next") {
+					return strings.Trim(target, "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// listQuery is the /api/models query of opts, leaving out unset options
+func listQuery(opts hfmodels.ListModelsOptions) url.Values {
+	query := url.Values{}
+	if opts.Search != "" {
+		query.Set("search", opts.Search)
+	}
+	if opts.Author != "" {
+		query.Set("author", opts.Author)
+	}
+	if opts.LibraryName != "" {
+		query.Set("library", opts.LibraryName)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	if opts.Direction != 0 {
+		query.Set("direction", strconv.Itoa(opts.Direction))
+	}
+	return query
+}
+
+// GetAvailableQuants lists the GGUF quantizations of a repo
+func (c *Client) GetAvailableQuants(id string) ([]string, error) {
+	details, err := c.GetModelDetails(id)
+	if err != nil {
+		return nil, err
+	}
+	return hfmodels.ExtractQuantsFromSiblings(details.Siblings), nil
+}
+
+// GetModelDetails returns the details of a repo
+func (c *Client) GetModelDetails(id string) (*hfmodels.ModelDetails, error) {
+	var details hfmodels.ModelDetails
+	if err := c.getJSON(context.Background(), "/api/models/"+escapeRepo(id), &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// Ping checks that the Hub answers API requests and returns how long the
+// round trip took
+func (c *Client) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	var models []hfmodels.Model
+	err := c.getJSON(ctx, "/api/models?limit=1", &models)
+	return time.Since(start), err
+}
+
+// WhoAmI returns the account name of the client's token
+func (c *Client) WhoAmI(ctx context.Context) (string, error) {
+	var account struct {
+		Name string `json:"name"`
+	}
+	if err := c.getJSON(ctx, "/api/whoami-v2", &account); err != nil {
+		return "", err
+	}
+	return account.Name, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	_, err := c.get(ctx, c.Endpoint+path, v)
+	return err
+}
+
+// get decodes the JSON response of u into v and returns its headers
+func (c *Client) get(ctx context.Context, u string, v any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Code: resp.StatusCode, Status: resp.Status, URL: req.URL.String()}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", req.URL, err)
+	}
+	return resp.Header, nil
+}
+
+// escapeRepo escapes the parts of an "org/name" repo id
+func escapeRepo(id string) string {
+	parts := strings.Split(id, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// StatusError is an unexpected HTTP response from the Hub
+type StatusError struct {
+	Code   int
+	Status string
+	URL    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected response %s", e.URL, e.Status)
+}
+
+// StatusCode returns the HTTP status code, for AccessHint
+func (e *StatusError) StatusCode() int {
+	return e.Code
+}
@@ -0,0 +1,140 @@
+package hf
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	hfmodels "github.com/Megatherium/hf-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mirrorHub stands in for a self-hosted Hub serving the REST API. Requests
+// with another token than "hf_good" are rejected; its queries are recorded.
+func mirrorHub(t *testing.T) (*httptest.Server, *[]url.Values) {
+	var queries []url.Values
+	auth := func(w http.ResponseWriter, r *http.Request) bool {
+		if h := r.Header.Get("Authorization"); h != "" && h != "Bearer hf_good" {
+			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models", func(w http.ResponseWriter, r *http.Request) {
+		if !auth(w, r) {
+			return
+		}
+		queries = append(queries, r.URL.Query())
+		json.NewEncoder(w).Encode([]hfmodels.Model{{ID: "org/repo-GGUF"}})
+	})
+	mux.HandleFunc("/api/models/org/repo-GGUF", func(w http.ResponseWriter, r *http.Request) {
+		if !auth(w, r) {
+			return
+		}
+		w.Write([]byte(`{"id":"org/repo-GGUF","likes":3,"pipeline_tag":"text-generation",
+			"siblings":[{"rfilename":"repo-Q4_K_M.gguf"}]}`))
+	})
+	mux.HandleFunc("/api/whoami-v2", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hf_good" {
+			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"type":"user","name":"alice"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &queries
+}
+
+func TestClient_SearchQuery(t *testing.T) {
+	srv, queries := mirrorHub(t)
+	c := NewClient(srv.URL+"/", "")
+
+	page, err := c.SearchPage(SearchOptions{Search: "qwen 8b", Limit: 20}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []hfmodels.Model{{ID: "org/repo-GGUF"}}, page.Models)
+	assert.Empty(t, page.Next)
+	require.Len(t, *queries, 1)
+	q := (*queries)[0]
+	assert.Equal(t, "qwen 8b", q.Get("search"))
+	assert.Equal(t, "gguf", q.Get("library"))
+	assert.Equal(t, "20", q.Get("limit"))
+	assert.Equal(t, "downloads", q.Get("sort"))
+	assert.Equal(t, "-1", q.Get("direction"))
+	assert.False(t, q.Has("author"), "unset options are left out")
+	assert.False(t, q.Has("pipeline_tag"))
+
+	_, err = c.SearchPage(SearchOptions{Search: "bge", PipelineTag: "feature-extraction", License: "mit"}, "")
+	require.NoError(t, err)
+	require.Len(t, *queries, 2)
+	q = (*queries)[1]
+	assert.Equal(t, "feature-extraction", q.Get("pipeline_tag"))
+	assert.Equal(t, "license:mit", q.Get("filter"))
+	assert.Equal(t, "gguf", q.Get("library"))
+}
+
+func TestClient_GetModelDetails(t *testing.T) {
+	srv, _ := mirrorHub(t)
+	c := NewClient(srv.URL, "hf_good")
+
+	details, err := c.GetModelDetails("org/repo-GGUF")
+	require.NoError(t, err)
+	assert.Equal(t, "org/repo-GGUF", details.ID)
+	assert.Equal(t, 3, details.Likes)
+	assert.Equal(t, "text-generation", details.PipelineTag)
+	assert.Equal(t, []hfmodels.Sibling{{RFilename: "repo-Q4_K_M.gguf"}}, details.Siblings)
+
+	_, err = c.GetModelDetails("org/missing")
+	var status *StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusNotFound, status.Code)
+	assert.Empty(t, AccessHint(err, "", "org/missing", true))
+}
+
+func TestClient_Checks(t *testing.T) {
+	srv, _ := mirrorHub(t)
+	ctx := context.Background()
+
+	c := NewClient(srv.URL, "hf_good")
+	_, err := c.Ping(ctx)
+	require.NoError(t, err)
+	name, err := c.WhoAmI(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", name)
+
+	c.Token = "hf_bad"
+	_, err = c.WhoAmI(ctx)
+	assert.Contains(t, AccessHint(err, "", "", true), "rejected the access token")
+
+	srv.Close()
+	_, err = c.Ping(ctx)
+	assert.Error(t, err)
+}
+
+func TestEndpoint(t *testing.T) {
+	t.Setenv("HF_ENDPOINT", "")
+	endpoint, source := Endpoint("")
+	assert.Equal(t, DefaultEndpoint, endpoint)
+	assert.Empty(t, source)
+
+	endpoint, source = Endpoint("http://mirror.lan:8080/")
+	assert.Equal(t, "http://mirror.lan:8080", endpoint)
+	assert.Equal(t, "hf_endpoint", source)
+
+	t.Setenv("HF_ENDPOINT", "https://hf-mirror.com")
+	endpoint, source = Endpoint("http://mirror.lan:8080")
+	assert.Equal(t, "https://hf-mirror.com", endpoint)
+	assert.Equal(t, "HF_ENDPOINT", source)
+}
+
+func TestCacheDir(t *testing.T) {
+	assert.Equal(t, "/cache/hf", CacheDir("/cache/hf", DefaultEndpoint))
+	assert.Equal(t, "/cache/hf/mirror.lan_8080", CacheDir("/cache/hf", "http://mirror.lan:8080"))
+	assert.Equal(t, "/cache/hf/example.com_hub", CacheDir("/cache/hf", "https://example.com/hub"))
+	assert.Empty(t, CacheDir("", "http://mirror.lan:8080"))
+}
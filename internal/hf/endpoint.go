@@ -0,0 +1,55 @@
+package hf
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	hfmodels "github.com/Megatherium/hf-go"
+)
+
+// DefaultEndpoint is the HuggingFace Hub
+const DefaultEndpoint = "https://huggingface.co"
+
+// Endpoint finds the Hub's base URL: the HF_ENDPOINT environment
+// variable, then configured (the hf_endpoint config key), then
+// DefaultEndpoint. source says which one it was, "" for the default.
+func Endpoint(configured string) (endpoint, source string) {
+	if endpoint := strings.TrimSpace(os.Getenv("HF_ENDPOINT")); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/"), "HF_ENDPOINT"
+	}
+	if endpoint := strings.TrimSpace(configured); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/"), "hf_endpoint"
+	}
+	return DefaultEndpoint, ""
+}
+
+// NewAPI returns the client for endpoint: hf-go's for the public Hub and
+// a Client speaking the same REST API for any other.
+func NewAPI(endpoint, token string) API {
+	if endpoint == DefaultEndpoint {
+		return hubClient{Client: hfmodels.NewClient(token), rest: NewClient(endpoint, token)}
+	}
+	return NewClient(endpoint, token)
+}
+
+// CacheDir keeps the responses of other endpoints apart from the public
+// Hub's: they go to a subdirectory of dir named after the endpoint's host
+func CacheDir(dir, endpoint string) string {
+	if dir == "" || endpoint == DefaultEndpoint {
+		return dir
+	}
+	name := endpoint
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		name = u.Host + u.Path
+	}
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':':
+			return '_'
+		}
+		return r
+	}, strings.Trim(name, "/"))
+	return filepath.Join(dir, name)
+}
@@ -0,0 +1,186 @@
+package hf
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxReadme bounds how much of a README is read
+const maxReadme = 1 << 20
+
+// ErrNoReadme is returned for repos without a README.md
+var ErrNoReadme = errors.New("the repo has no model card")
+
+// ReadmeAPI fetches model cards; Client implements it
+type ReadmeAPI interface {
+	// Revision returns the commit of the repo's main branch, "" if the Hub
+	// does not say
+	Revision(id string) (string, error)
+	// Readme returns README.md of the repo at revision
+	Readme(id, revision string) (string, error)
+}
+
+// Readme is a repo's model card
+type Readme struct {
+	// Revision is the commit it was read at, "" if unknown
+	Revision string
+	// Text is the markdown without its YAML front matter
+	Text string
+}
+
+// Revision returns the commit of the repo's main branch from the
+// X-Repo-Commit header of its README, "" if the Hub does not send it
+func (c *Client) Revision(id string) (string, error) {
+	req, err := c.readmeRequest(http.MethodHead, id, "main")
+	if err != nil {
+		return "", err
+	}
+	// The header is on the first response, redirects are not needed
+	client := *c.HTTP
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if err := readmeStatus(req, resp); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("X-Repo-Commit"), nil
+}
+
+// Readme returns README.md of the repo at revision
+func (c *Client) Readme(id, revision string) (string, error) {
+	req, err := c.readmeRequest(http.MethodGet, id, revision)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := readmeStatus(req, resp); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxReadme))
+	return string(data), err
+}
+
+func (c *Client) readmeRequest(method, id, revision string) (*http.Request, error) {
+	u := c.Endpoint + "/" + escapeRepo(id) + "/resolve/" + url.PathEscape(revision) + "/README.md"
+	req, err := http.NewRequestWithContext(context.Background(), method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return req, nil
+}
+
+// readmeStatus turns unexpected responses into errors, ErrNoReadme for 404
+func readmeStatus(req *http.Request, resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNoReadme
+	case resp.StatusCode >= 400:
+		return &StatusError{Code: resp.StatusCode, Status: resp.Status, URL: req.URL.String()}
+	}
+	return nil
+}
+
+func (c hubClient) Revision(id string) (string, error) {
+	return c.rest.Revision(id)
+}
+
+func (c hubClient) Readme(id, revision string) (string, error) {
+	return c.rest.Readme(id, revision)
+}
+
+// GetReadme returns the model card of a repo. Cards are cached per
+// revision, so one is only downloaded again after the repo changed. If
+// the Hub cannot be reached the last cached card is returned marked stale.
+func (c *Cache) GetReadme(id string) (Readme, Info, error) {
+	api, ok := c.api.(ReadmeAPI)
+	if !ok {
+		return Readme{}, Info{}, ErrNoReadme
+	}
+	readme, info, err := c.fetchReadme(api, id)
+	if err == nil {
+		readme.Text = stripFrontMatter(readme.Text)
+		return readme, info, nil
+	}
+	// Missing cards and access errors are shown as such
+	if errors.Is(err, ErrNoReadme) || AccessHint(err, "", "", false) != "" {
+		return Readme{}, Info{}, err
+	}
+	entry, cached := c.load("readme:" + id)
+	var last Readme
+	if !cached || json.Unmarshal(entry.Data, &last) != nil {
+		return Readme{}, Info{}, err
+	}
+	last.Text = stripFrontMatter(last.Text)
+	return last, Info{FetchedAt: entry.FetchedAt, Cached: true, Stale: true, Err: err}, nil
+}
+
+// fetchReadme looks up the revision of a repo and reads its card from the
+// cache, or from the Hub if this revision was not read before. The last
+// card read is also kept apart for offline use.
+func (c *Cache) fetchReadme(api ReadmeAPI, id string) (Readme, Info, error) {
+	revision, err := api.Revision(id)
+	if err != nil {
+		return Readme{}, Info{}, err
+	}
+	key := "readme:" + id + "@" + revision
+	if revision != "" {
+		if entry, ok := c.load(key); ok {
+			var readme Readme
+			if json.Unmarshal(entry.Data, &readme) == nil {
+				return readme, Info{FetchedAt: entry.FetchedAt, Cached: true}, nil
+			}
+		}
+	}
+
+	text, err := api.Readme(id, cmp.Or(revision, "main"))
+	if err != nil {
+		return Readme{}, Info{}, err
+	}
+	readme := Readme{Revision: revision, Text: text}
+	now := c.now()
+	if revision != "" {
+		c.store(key, now, readme)
+	}
+	c.store("readme:"+id, now, readme)
+	return readme, Info{FetchedAt: now}, nil
+}
+
+// stripFrontMatter removes the YAML block between "---" lines that model
+// cards start with
+func stripFrontMatter(text string) string {
+	text = strings.TrimPrefix(text, "\ufeff")
+	rest, ok := strings.CutPrefix(text, "---")
+	if !ok {
+		return text
+	}
+	rest = strings.TrimLeft(rest, " \t")
+	if !strings.HasPrefix(rest, "\n") && !strings.HasPrefix(rest, "\r\n") {
+		return text
+	}
+	body := rest
+	for line := range strings.Lines(rest) {
+		body = body[len(line):]
+		if trimmed := strings.TrimRight(line, " \t\r\n"); trimmed == "---" || trimmed == "..." {
+			return strings.TrimLeft(body, "\r\n")
+		}
+	}
+	return text
+}
@@ -0,0 +1,108 @@
+package hf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cardHub serves the README of org/repo at the commit in *commit and
+// counts the READMEs it sent
+func cardHub(t *testing.T, commit *string) (*httptest.Server, *atomic.Int32) {
+	var reads atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/repo/resolve/{rev}/README.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Repo-Commit", *commit)
+		if r.Method == http.MethodHead {
+			return
+		}
+		reads.Add(1)
+		w.Write([]byte("---\nlicense: mit\ntags:\n- gguf\n---\n\n# Card at " + r.PathValue("rev") + "\n"))
+	})
+	mux.HandleFunc("/org/gated/resolve/{rev}/README.md", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Access to model org/gated is restricted", http.StatusForbidden)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &reads
+}
+
+func TestCache_GetReadme(t *testing.T) {
+	commit := "abc123"
+	srv, reads := cardHub(t, &commit)
+	c := NewCache(NewClient(srv.URL, ""), t.TempDir(), time.Minute)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	readme, info, err := c.GetReadme("org/repo")
+	require.NoError(t, err)
+	assert.Equal(t, Readme{Revision: "abc123", Text: "# Card at abc123\n"}, readme)
+	assert.False(t, info.Cached)
+
+	now = now.Add(24 * time.Hour)
+	readme, info, err = c.GetReadme("org/repo")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", readme.Revision)
+	assert.True(t, info.Cached, "cards do not expire within a revision")
+	assert.Equal(t, int32(1), reads.Load())
+
+	commit = "def456"
+	readme, info, err = c.GetReadme("org/repo")
+	require.NoError(t, err)
+	assert.Equal(t, "# Card at def456\n", readme.Text)
+	assert.False(t, info.Cached)
+	assert.Equal(t, int32(2), reads.Load())
+
+	_, _, err = c.GetReadme("org/missing")
+	assert.ErrorIs(t, err, ErrNoReadme)
+	_, _, err = c.GetReadme("org/gated")
+	assert.Contains(t, AccessHint(err, "", "org/gated", true), "is gated")
+
+	srv.Close()
+	readme, info, err = c.GetReadme("org/repo")
+	require.NoError(t, err)
+	assert.Equal(t, "# Card at def456\n", readme.Text)
+	assert.True(t, info.Stale)
+	assert.Error(t, info.Err)
+}
+
+func TestCache_GetReadmeWithoutCommit(t *testing.T) {
+	commit := ""
+	srv, reads := cardHub(t, &commit)
+	c := NewCache(NewClient(srv.URL, ""), t.TempDir(), time.Minute)
+
+	for range 2 {
+		readme, _, err := c.GetReadme("org/repo")
+		require.NoError(t, err)
+		assert.Equal(t, Readme{Text: "# Card at main\n"}, readme)
+	}
+	assert.Equal(t, int32(2), reads.Load(), "without a revision the card is read every time")
+}
+
+func TestStripFrontMatter(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"none", "# Title\n\ntext\n", "# Title\n\ntext\n"},
+		{"front matter", "---\nlicense: mit\n---\n# Title\n", "# Title\n"},
+		{"blank lines after", "---\nlicense: mit\n---\n\n\n# Title\n", "# Title\n"},
+		{"crlf", "---\r\nlicense: mit\r\n---\r\n# Title\r\n", "# Title\r\n"},
+		{"dots end", "---\nlicense: mit\n...\n# Title\n", "# Title\n"},
+		{"bom", "\ufeff---\nlicense: mit\n---\n# Title\n", "# Title\n"},
+		{"unterminated", "---\nlicense: mit\n", "---\nlicense: mit\n"},
+		{"rule", "--- not front matter\n", "--- not front matter\n"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stripFrontMatter(tt.in))
+		})
+	}
+}
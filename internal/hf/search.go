@@ -0,0 +1,78 @@
+package hf
+
+import (
+	hfmodels "github.com/Megatherium/hf-go"
+)
+
+// Sort orders of a search, most first
+const (
+	SortDownloads = "downloads"
+	SortLikes     = "likes"
+	SortUpdated   = "lastModified"
+)
+
+// Sorts are the sort orders in the order the search options cycle them
+var Sorts = []string{SortDownloads, SortLikes, SortUpdated}
+
+// SortLabel names a sort order for display
+func SortLabel(sort string) string {
+	switch sort {
+	case "", SortDownloads:
+		return "downloads"
+	case SortLikes:
+		return "likes"
+	case SortUpdated:
+		return "recently updated"
+	}
+	return sort
+}
+
+// SearchOptions is a search for GGUF repos
+type SearchOptions struct {
+	Search string
+	Author string
+	// Sort is one of Sorts, SortDownloads if ""
+	Sort string
+	// PipelineTag is the task, such as "text-generation" or
+	// "feature-extraction" for embeddings
+	PipelineTag string
+	// License is a license ID such as "apache-2.0"
+	License string
+	// Limit is the number of results per page
+	Limit int
+}
+
+// ListModelsOptions converts the search to hf-go's options, which the
+// REST query is built from
+func (o SearchOptions) ListModelsOptions() hfmodels.ListModelsOptions {
+	sort := o.Sort
+	if sort == "" {
+		sort = SortDownloads
+	}
+	return hfmodels.ListModelsOptions{
+		Search:      o.Search,
+		Author:      o.Author,
+		LibraryName: "gguf",
+		Limit:       o.Limit,
+		Sort:        sort,
+		Direction:   -1,
+	}
+}
+
+// Page is one page of search results
+type Page struct {
+	Models []hfmodels.Model `json:"models"`
+	// Next is the URL of the following page, "" on the last one
+	Next string `json:"next,omitempty"`
+}
+
+// hubClient is hf-go's client for the public Hub, with the REST client
+// for searches
+type hubClient struct {
+	*hfmodels.Client
+	rest *Client
+}
+
+func (c hubClient) SearchPage(opts SearchOptions, next string) (Page, error) {
+	return c.rest.SearchPage(opts, next)
+}
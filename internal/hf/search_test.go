@@ -0,0 +1,101 @@
+package hf
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	hfmodels "github.com/Megatherium/hf-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchOptions_ListModelsOptions(t *testing.T) {
+	opts := SearchOptions{Search: "qwen", Author: "unsloth", Limit: 40}
+	assert.Equal(t, hfmodels.ListModelsOptions{
+		Search:      "qwen",
+		Author:      "unsloth",
+		LibraryName: "gguf",
+		Limit:       40,
+		Sort:        SortDownloads,
+		Direction:   -1,
+	}, opts.ListModelsOptions())
+
+	opts.Sort = SortUpdated
+	assert.Equal(t, "lastModified", opts.ListModelsOptions().Sort)
+}
+
+// pagingHub serves three pages of one result each, linking the next page
+// with a cursor like the Hub does: absolutely from the first page and
+// relatively from the second. It counts the requests it got.
+func pagingHub(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch cursor := r.URL.Query().Get("cursor"); cursor {
+		case "":
+			w.Header().Set("Link", `<`+srv.URL+`/api/models?search=qwen&cursor=p2>; rel="next"`)
+			json.NewEncoder(w).Encode([]hfmodels.Model{{ID: "org/one"}})
+		case "p2":
+			w.Header().Set("Link", `</api/models?search=qwen&cursor=p3>; rel="next"`)
+			json.NewEncoder(w).Encode([]hfmodels.Model{{ID: "org/two"}})
+		default:
+			json.NewEncoder(w).Encode([]hfmodels.Model{{ID: "org/three"}})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestClient_SearchPage(t *testing.T) {
+	srv, _ := pagingHub(t)
+	c := NewClient(srv.URL, "")
+	opts := SearchOptions{Search: "qwen", Limit: 1}
+
+	var ids []string
+	next := ""
+	for i := 0; i == 0 || next != ""; i++ {
+		require.Less(t, i, 3)
+		page, err := c.SearchPage(opts, next)
+		require.NoError(t, err)
+		for _, model := range page.Models {
+			ids = append(ids, model.ID)
+		}
+		next = page.Next
+	}
+	assert.Equal(t, []string{"org/one", "org/two", "org/three"}, ids)
+
+	_, err := c.SearchPage(opts, "https://elsewhere.example/api/models?cursor=p2")
+	assert.ErrorContains(t, err, "is not on", "the token is not sent to other hosts")
+}
+
+func TestCache_SearchPage(t *testing.T) {
+	srv, requests := pagingHub(t)
+	c := NewCache(NewClient(srv.URL, ""), t.TempDir(), time.Hour)
+	opts := SearchOptions{Search: "qwen", Limit: 1}
+
+	first, _, err := c.SearchPage(opts, "")
+	require.NoError(t, err)
+	second, _, err := c.SearchPage(opts, first.Next)
+	require.NoError(t, err)
+	assert.Equal(t, []hfmodels.Model{{ID: "org/two"}}, second.Models)
+	assert.Equal(t, int32(2), requests.Load(), "each page is fetched once")
+
+	again, info, err := c.SearchPage(opts, first.Next)
+	require.NoError(t, err)
+	assert.True(t, info.Cached)
+	assert.Equal(t, second, again)
+	assert.Equal(t, int32(2), requests.Load(), "pages are cached by their cursor")
+}
+
+func TestNextLink(t *testing.T) {
+	assert.Equal(t, "https://hub/api/models?cursor=x",
+		nextLink(`<https://hub/api/models?cursor=x>; rel="next"`))
+	assert.Equal(t, "/b", nextLink(`</a>; rel="prev", </b>; rel=next`))
+	assert.Empty(t, nextLink(`</a>; rel="prev"`))
+	assert.Empty(t, nextLink(""))
+}
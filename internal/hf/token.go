@@ -0,0 +1,94 @@
+package hf
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Token finds the HuggingFace access token: the HF_TOKEN environment
+// variable, then configured (the hf_token config key), then the file
+// huggingface-cli login writes. source says which one it was, token is ""
+// if there is none.
+func Token(configured string) (token, source string) {
+	if token := strings.TrimSpace(os.Getenv("HF_TOKEN")); token != "" {
+		return token, "HF_TOKEN"
+	}
+	if token := strings.TrimSpace(configured); token != "" {
+		return token, "hf_token"
+	}
+	path := TokenFile()
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, path
+		}
+	}
+	return "", ""
+}
+
+// TokenFile is where huggingface_hub keeps the token: $HF_TOKEN_PATH,
+// $HF_HOME/token or ~/.cache/huggingface/token
+func TokenFile() string {
+	if path := os.Getenv("HF_TOKEN_PATH"); path != "" {
+		return path
+	}
+	if home := os.Getenv("HF_HOME"); home != "" {
+		return filepath.Join(home, "token")
+	}
+	if cache := os.Getenv("XDG_CACHE_HOME"); cache != "" {
+		return filepath.Join(cache, "huggingface", "token")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cache", "huggingface", "token")
+}
+
+// statusPattern finds the HTTP status in errors that only carry it as
+// text
+var statusPattern = regexp.MustCompile(`\b(401|403)\b|(?i)unauthorized|forbidden`)
+
+// AccessHint explains an error caused by a missing token or a gated repo,
+// and returns "" for other errors. Errors with a StatusCode() int method
+// are checked by code, others by their text. endpoint is the Hub the repo
+// is on, "" for huggingface.co; repo is "" for requests such as searches
+// that are not about one repo.
+func AccessHint(err error, endpoint, repo string, haveToken bool) string {
+	if err == nil {
+		return ""
+	}
+	code := 0
+	var status interface{ StatusCode() int }
+	if errors.As(err, &status) {
+		code = status.StatusCode()
+	} else if match := statusPattern.FindString(err.Error()); match != "" {
+		code, _ = strconv.Atoi(match)
+		if code == 0 {
+			code = 401
+			if strings.EqualFold(match, "forbidden") {
+				code = 403
+			}
+		}
+	}
+
+	switch {
+	case code == 401 && haveToken:
+		return "HuggingFace rejected the access token (401). Check HF_TOKEN, hf_token or run huggingface-cli login again."
+	case code == 401 && repo == "":
+		return "HuggingFace asks for an access token (401). Set HF_TOKEN or hf_token, or run huggingface-cli login."
+	case code == 403 && repo == "":
+		return "HuggingFace denied access (403). Check that your token has read access."
+	case code == 401:
+		return fmt.Sprintf("%s is private or gated and needs a HuggingFace token (401). Set HF_TOKEN or hf_token, or run huggingface-cli login.", repo)
+	case code == 403:
+		endpoint = strings.TrimSuffix(cmp.Or(endpoint, DefaultEndpoint), "/")
+		return fmt.Sprintf("%s is gated (403). Accept its license at %s/%s with the account of your token.", repo, endpoint, repo)
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package hf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToken(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HF_HOME", home)
+	t.Setenv("HF_TOKEN_PATH", "")
+	t.Setenv("HF_TOKEN", "")
+
+	token, source := Token("")
+	assert.Empty(t, token)
+	assert.Empty(t, source)
+
+	file := filepath.Join(home, "token")
+	require.NoError(t, os.WriteFile(file, []byte("hf_file\n"), 0600))
+	token, source = Token("")
+	assert.Equal(t, "hf_file", token)
+	assert.Equal(t, file, source)
+
+	token, source = Token("hf_config")
+	assert.Equal(t, "hf_config", token)
+	assert.Equal(t, "hf_token", source)
+
+	t.Setenv("HF_TOKEN", "hf_env")
+	token, source = Token("hf_config")
+	assert.Equal(t, "hf_env", token)
+	assert.Equal(t, "HF_TOKEN", source)
+}
+
+func TestTokenFile(t *testing.T) {
+	t.Setenv("HF_TOKEN_PATH", "/run/secrets/hf")
+	assert.Equal(t, "/run/secrets/hf", TokenFile())
+
+	t.Setenv("HF_TOKEN_PATH", "")
+	t.Setenv("HF_HOME", "/data/hf")
+	assert.Equal(t, "/data/hf/token", TokenFile())
+
+	t.Setenv("HF_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "/tmp/cache")
+	assert.Equal(t, "/tmp/cache/huggingface/token", TokenFile())
+}
+
+type statusErr int
+
+func (e statusErr) Error() string   { return "request failed" }
+func (e statusErr) StatusCode() int { return int(e) }
+
+func TestAccessHint(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		endpoint  string
+		haveToken bool
+		want      string
+	}{
+		{"no error", nil, "", false, ""},
+		{"other error", errors.New("connection refused"), "", false, ""},
+		{"not found", statusErr(404), "", true, ""},
+		{"needs token", statusErr(401), "", false, "needs a HuggingFace token"},
+		{"bad token", fmt.Errorf("fetch: %w", statusErr(401)), "", true, "rejected the access token"},
+		{"gated", statusErr(403), "", true, "Accept its license at https://huggingface.co/meta-llama/Llama-3.1-8B"},
+		{"gated on mirror", statusErr(403), "https://hf-mirror.example/", true, "Accept its license at https://hf-mirror.example/meta-llama/Llama-3.1-8B"},
+		{"text code", errors.New("API request failed with status 403"), "", true, "is gated"},
+		{"text reason", errors.New("401 Unauthorized"), "", false, "needs a HuggingFace token"},
+		{"forbidden", errors.New("Forbidden"), "", true, "is gated"},
+		{"port number", errors.New("dial tcp 127.0.0.1:4013: refused"), "", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hint := AccessHint(tt.err, tt.endpoint, "meta-llama/Llama-3.1-8B", tt.haveToken)
+			if tt.want == "" {
+				assert.Empty(t, hint)
+			} else {
+				assert.Contains(t, hint, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessHint_WithoutRepo(t *testing.T) {
+	assert.Contains(t, AccessHint(statusErr(401), "", "", false), "asks for an access token")
+	assert.Contains(t, AccessHint(statusErr(401), "", "", true), "rejected the access token")
+	hint := AccessHint(statusErr(403), "", "", true)
+	assert.Contains(t, hint, "denied access")
+	assert.NotContains(t, hint, "huggingface.co/", "no link to a repo")
+}
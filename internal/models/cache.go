@@ -0,0 +1,208 @@
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// LlamaCacheDir returns the directory llama.cpp downloads -hf models to
+func LlamaCacheDir() string {
+	if dir := os.Getenv("LLAMA_CACHE"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Caches", "llama.cpp")
+	case "windows":
+		return filepath.Join(os.Getenv("LOCALAPPDATA"), "llama.cpp")
+	}
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "llama.cpp")
+	}
+	return filepath.Join(home, ".cache", "llama.cpp")
+}
+
+// HFCacheDir returns the huggingface_hub cache directory
+func HFCacheDir() string {
+	if dir := os.Getenv("HF_HUB_CACHE"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("HF_HOME"); dir != "" {
+		return filepath.Join(dir, "hub")
+	}
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "huggingface", "hub")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "huggingface", "hub")
+}
+
+// llamaManifest is the part of a cached HF manifest that llama.cpp writes
+// as manifest=<org>=<repo>=<tag>.json next to the downloaded files
+type llamaManifest struct {
+	GGUFFile *struct {
+		RFilename string `json:"rfilename"`
+	} `json:"ggufFile"`
+	MMProjFile *struct {
+		RFilename string `json:"rfilename"`
+	} `json:"mmprojFile"`
+}
+
+type llamaCacheEntry struct {
+	repo   string
+	file   string
+	quant  string
+	mmproj string // cached file name of the projector
+}
+
+// llamaCacheFile returns the name llama.cpp stores a repo file under
+func llamaCacheFile(repo, file string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(repo + "_" + file)
+}
+
+// scanLlamaCache adds the GGUF files in llama.cpp's cache. The repo and
+// quant of each file are recovered from the manifests saved beside them.
+func (s *scanner) scanLlamaCache(dir string) {
+	entries := make(map[string]llamaCacheEntry)
+	manifests, _ := filepath.Glob(filepath.Join(dir, "manifest=*.json"))
+	for _, path := range manifests {
+		parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "manifest="), ".json"), "=")
+		if len(parts) < 3 {
+			continue
+		}
+		repo := strings.Join(parts[:len(parts)-1], "/")
+		tag := parts[len(parts)-1]
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var manifest llamaManifest
+		if err := json.Unmarshal(data, &manifest); err != nil || manifest.GGUFFile == nil {
+			s.logger.Debug("Skipping unreadable llama.cpp manifest", zap.String("file", path), zap.Error(err))
+			continue
+		}
+
+		entry := llamaCacheEntry{repo: repo, file: manifest.GGUFFile.RFilename, quant: tag}
+		if strings.EqualFold(tag, "latest") {
+			entry.quant = quantFromFilename(entry.file)
+		}
+		if manifest.MMProjFile != nil {
+			entry.mmproj = llamaCacheFile(repo, manifest.MMProjFile.RFilename)
+		}
+		entries[llamaCacheFile(repo, entry.file)] = entry
+	}
+
+	err := s.scanRoot(scanRoot{
+		dir:      dir,
+		source:   SourceLlamaCache,
+		ggufOnly: true,
+		annotate: func(m *Model) {
+			base := filepath.Base(m.Path)
+			entry, ok := entries[base]
+			if !ok {
+				m.HFQuant = quantFromFilename(base)
+				return
+			}
+			m.Name = entry.repo + "/" + entry.file
+			m.HFRepo = entry.repo
+			m.HFQuant = entry.quant
+			if entry.mmproj != "" {
+				m.Projector = &Model{Name: entry.mmproj, Path: filepath.Join(dir, entry.mmproj)}
+			}
+		},
+	})
+	if err != nil {
+		s.logger.Debug("Skipping llama.cpp cache", zap.String("directory", dir), zap.Error(err))
+	}
+}
+
+// scanHFCache adds the GGUF files of every models--<org>--<name> repo in a
+// huggingface_hub cache. Only snapshots that a ref points to are scanned,
+// or all of them when the refs are missing.
+func (s *scanner) scanHFCache(hub string) {
+	repos, err := os.ReadDir(hub)
+	if err != nil {
+		s.logger.Debug("Skipping HuggingFace cache", zap.String("directory", hub), zap.Error(err))
+		return
+	}
+
+	for _, entry := range repos {
+		name, ok := strings.CutPrefix(entry.Name(), "models--")
+		if !ok || !entry.IsDir() {
+			continue
+		}
+		repo := strings.Replace(name, "--", "/", 1)
+		repoDir := filepath.Join(hub, entry.Name())
+
+		for _, snapshot := range hfSnapshots(repoDir) {
+			err := s.scanRoot(scanRoot{
+				dir:      snapshot,
+				depth:    5,
+				prefix:   repo + "/",
+				source:   SourceHFCache,
+				ggufOnly: true,
+				annotate: func(m *Model) {
+					m.HFRepo = repo
+					m.HFQuant = quantFromFilename(filepath.Base(m.Path))
+				},
+			})
+			if err != nil {
+				s.logger.Debug("Skipping snapshot", zap.String("directory", snapshot), zap.Error(err))
+			}
+		}
+	}
+}
+
+// hfSnapshots returns the snapshot directories of a cached repo
+func hfSnapshots(repoDir string) []string {
+	var snapshots []string
+	refs, _ := os.ReadDir(filepath.Join(repoDir, "refs"))
+	for _, ref := range refs {
+		data, err := os.ReadFile(filepath.Join(repoDir, "refs", ref.Name()))
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join(repoDir, "snapshots", strings.TrimSpace(string(data)))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			snapshots = append(snapshots, dir)
+		}
+	}
+	if len(snapshots) > 0 {
+		return snapshots
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(repoDir, "snapshots"))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			snapshots = append(snapshots, filepath.Join(repoDir, "snapshots", entry.Name()))
+		}
+	}
+	return snapshots
+}
+
+// quantTag matches a quantization or precision tag such as Q4_K_M or BF16
+var quantTag = regexp.MustCompile(`(?i)^(i?q\d(_[a-z0-9]+)*|f16|f32|bf16)$`)
+
+// quantFromFilename picks the quantization tag out of a GGUF file name,
+// e.g. "Q4_K_XL" from "Qwen3-8B-UD-Q4_K_XL-00001-of-00002.gguf"
+func quantFromFilename(name string) string {
+	name = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	if prefix, _, _, ok := parseSplitName(name + ".gguf"); ok {
+		name = prefix
+	}
+	tokens := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '.' })
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if quantTag.MatchString(tokens[i]) {
+			return strings.ToUpper(tokens[i])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+)
+
+func TestQuantFromFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Qwen3-8B-Q4_K_M.gguf", "Q4_K_M"},
+		{"Qwen3-8B-UD-Q4_K_XL-00001-of-00002.gguf", "Q4_K_XL"},
+		{"gemma-3-4b-it-iq4_xs.gguf", "IQ4_XS"},
+		{"mmproj-model-f16.gguf", "F16"},
+		{"Llama-3.2-1B-Instruct.Q8_0.gguf", "Q8_0"},
+		{"model.gguf", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, quantFromFilename(tt.name))
+		})
+	}
+}
+
+func TestCacheDirs(t *testing.T) {
+	t.Setenv("LLAMA_CACHE", "/tmp/llama")
+	t.Setenv("HF_HUB_CACHE", "")
+	t.Setenv("HF_HOME", "/tmp/hf")
+	assert.Equal(t, "/tmp/llama", LlamaCacheDir())
+	assert.Equal(t, filepath.Join("/tmp/hf", "hub"), HFCacheDir())
+
+	t.Setenv("HF_HUB_CACHE", "/tmp/hub")
+	assert.Equal(t, "/tmp/hub", HFCacheDir())
+}
+
+func TestDiscoverModels_LlamaCache(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("LLAMA_CACHE", cache)
+	writeFiles(t, cache,
+		"unsloth_Qwen3-8B-GGUF_Qwen3-8B-Q4_K_M.gguf",
+		"unsloth_Qwen3-8B-GGUF_Qwen3-8B-Q4_K_M.gguf.json",
+		"ggml-org_gemma-3-4b-it-GGUF_gemma-3-4b-it-Q4_K_M.gguf",
+		"ggml-org_gemma-3-4b-it-GGUF_mmproj-model-f16.gguf",
+		"someone_repo_weights-Q8_0.gguf",
+	)
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "manifest=unsloth=Qwen3-8B-GGUF=Q4_K_M.json"),
+		[]byte(`{"ggufFile":{"rfilename":"Qwen3-8B-Q4_K_M.gguf","size":5027783488}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "manifest=ggml-org=gemma-3-4b-it-GGUF=latest.json"),
+		[]byte(`{"ggufFile":{"rfilename":"gemma-3-4b-it-Q4_K_M.gguf"},"mmprojFile":{"rfilename":"mmproj-model-f16.gguf"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "manifest=broken=repo=Q4_0.json"), []byte(`{`), 0644))
+
+	cfg := &app.Config{ModelsDir: filepath.Join(t.TempDir(), "missing"), ScanLlamaCache: true}
+	found, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+
+	qwen := found[2]
+	assert.Equal(t, "unsloth/Qwen3-8B-GGUF/Qwen3-8B-Q4_K_M.gguf", qwen.Name)
+	assert.Equal(t, SourceLlamaCache, qwen.Source)
+	assert.Equal(t, "unsloth/Qwen3-8B-GGUF", qwen.HFRepo)
+	assert.Equal(t, "Q4_K_M", qwen.HFQuant)
+	assert.Equal(t, filepath.Join(cache, "unsloth_Qwen3-8B-GGUF_Qwen3-8B-Q4_K_M.gguf"), qwen.Path)
+
+	gemma := found[0]
+	assert.Equal(t, "ggml-org/gemma-3-4b-it-GGUF/gemma-3-4b-it-Q4_K_M.gguf", gemma.Name)
+	assert.Equal(t, "Q4_K_M", gemma.HFQuant)
+	require.NotNil(t, gemma.Projector)
+	assert.Equal(t, filepath.Join(cache, "ggml-org_gemma-3-4b-it-GGUF_mmproj-model-f16.gguf"), gemma.Projector.Path)
+
+	// Without a manifest only the quant can be recovered
+	unknown := found[1]
+	assert.Equal(t, "someone_repo_weights-Q8_0.gguf", unknown.Name)
+	assert.Equal(t, "", unknown.HFRepo)
+	assert.Equal(t, "Q8_0", unknown.HFQuant)
+}
+
+func TestDiscoverModels_HFCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hub cache uses symlinks")
+	}
+	hub := t.TempDir()
+	t.Setenv("HF_HUB_CACHE", hub)
+
+	repo := filepath.Join(hub, "models--bartowski--Llama-3.2-1B-Instruct-GGUF")
+	writeFiles(t, repo,
+		"blobs/aaa",
+		"blobs/bbb",
+		"refs/main",
+		"snapshots/old/Llama-3.2-1B-Instruct-Q8_0.gguf",
+	)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "refs", "main"), []byte("abc123\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "snapshots", "abc123", "sub"), 0755))
+	require.NoError(t, os.Symlink("../../blobs/aaa", filepath.Join(repo, "snapshots", "abc123", "Llama-3.2-1B-Instruct-Q4_K_M.gguf")))
+	require.NoError(t, os.Symlink("../../../blobs/bbb", filepath.Join(repo, "snapshots", "abc123", "sub", "Llama-3.2-1B-Instruct-IQ4_XS.gguf")))
+
+	// Non-GGUF repos and stray files are ignored
+	writeFiles(t, hub,
+		"models--google-bert--bert-base/snapshots/x/pytorch_model.bin",
+		"models--google-bert--bert-base/snapshots/x/tokenizer.model",
+		"version.txt",
+	)
+
+	local := t.TempDir()
+	writeFiles(t, local, "local.gguf")
+
+	cfg := &app.Config{ModelsDir: local, ScanHFCache: true}
+	found, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+
+	assert.Equal(t, "local.gguf", found[0].Name)
+	assert.Equal(t, SourceLocal, found[0].Source)
+
+	assert.Equal(t, "bartowski/Llama-3.2-1B-Instruct-GGUF/Llama-3.2-1B-Instruct-Q4_K_M.gguf", found[1].Name)
+	assert.Equal(t, SourceHFCache, found[1].Source)
+	assert.Equal(t, "bartowski/Llama-3.2-1B-Instruct-GGUF", found[1].HFRepo)
+	assert.Equal(t, "Q4_K_M", found[1].HFQuant)
+
+	assert.Equal(t, "bartowski/Llama-3.2-1B-Instruct-GGUF/sub/Llama-3.2-1B-Instruct-IQ4_XS.gguf", found[2].Name)
+	assert.Equal(t, "IQ4_XS", found[2].HFQuant)
+}
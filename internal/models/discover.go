@@ -1,59 +1,347 @@
 package models
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"lloader/internal/app"
 )
 
+// Source tells where a model was discovered
+type Source string
+
+const (
+	// SourceLocal is a configured models directory
+	SourceLocal Source = "local"
+	// SourceLlamaCache is llama.cpp's download cache used by -hf
+	SourceLlamaCache Source = "llama.cpp"
+	// SourceHFCache is the huggingface_hub cache
+	SourceHFCache Source = "hf-cache"
+	// SourceOllama is an Ollama model store
+	SourceOllama Source = "ollama"
+	// SourceLMStudio is LM Studio's models directory
+	SourceLMStudio Source = "lmstudio"
+)
+
 type Model struct {
+	// Name is the path relative to Dir, using forward slashes. Cached
+	// models are named after their repo instead.
 	Name string
 	Path string
-	Size int64
+	// Dir is the models directory the model was found in
+	Dir    string
+	Size   int64
+	Source Source
+	// HFRepo and HFQuant are known for models from the download caches
+	HFRepo  string
+	HFQuant string
+	// Shards is the number of files of a split model, 0 for single files.
+	// Path then points at the first shard and Size covers all of them.
+	Shards int
+	// MissingShards lists the 1-based numbers of shards that were not found
+	MissingShards []int
+	// Projector is the multimodal projector (mmproj) paired with the model
+	Projector *Model
+	// GGUF is the parsed header, nil for other formats or unreadable files
+	GGUF *GGUFInfo
+	// SHA256 is the file's checksum if known, from the index or from the
+	// blob name in content-addressed stores
+	SHA256 string
+	// ModTime is the file's modification time
+	ModTime time.Time
+	// LastUsed is when the model was last launched, zero if never
+	LastUsed time.Time
 }
 
+// DiscoverModels walks every configured models directory and returns the
+// model files found in them. Names are paths relative to their models
+// directory, so equally named files in different folders stay apart.
+//
+// GGUF headers are taken from the index at cfg.IndexFile for files whose
+// size and modification time did not change, and the index is updated
+// with everything that had to be read.
 func DiscoverModels(cfg *app.Config, logger *zap.Logger) ([]Model, error) {
-	logger.Info("Discovering models", zap.String("directory", cfg.ModelsDir))
+	dirs := cfg.ModelDirs()
+	logger.Info("Discovering models", zap.Strings("directories", dirs), zap.Int("depth", cfg.ScanDepth))
 
-	entries, err := os.ReadDir(cfg.ModelsDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read models directory: %w", err)
+	s := &scanner{
+		logger:  logger,
+		ignore:  cfg.ScanIgnore,
+		visited: make(map[string]bool),
+		seen:    make(map[string]bool),
+		names:   make(map[string]bool),
+		indexed: make(map[string]bool),
 	}
+	if cfg.IndexFile != "" {
+		indexMu.Lock()
+		defer indexMu.Unlock()
 
-	var models []Model
-	for _, entry := range entries {
-		if entry.IsDir() {
+		var err error
+		if s.index, err = OpenIndex(cfg.IndexFile); err != nil {
+			logger.Warn("Ignoring model index", zap.String("file", cfg.IndexFile), zap.Error(err))
+		}
+	}
+
+	var firstErr error
+	scanned := 0
+	for _, dir := range dirs {
+		err := s.scanRoot(scanRoot{dir: dir, depth: cfg.ScanDepth, source: SourceLocal})
+		if errors.Is(err, fs.ErrNotExist) {
+			// A directory that was not created yet just has no models
+			logger.Warn("Models directory does not exist", zap.String("directory", dir))
+			scanned++
+			continue
+		}
+		if err != nil {
+			logger.Warn("Failed to read models directory", zap.String("directory", dir), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
 			continue
 		}
+		scanned++
+	}
+
+	if cfg.ScanLlamaCache {
+		s.scanLlamaCache(LlamaCacheDir())
+	}
+	if cfg.ScanHFCache {
+		s.scanHFCache(HFCacheDir())
+	}
+	if cfg.ScanOllama {
+		for _, dir := range OllamaDirs() {
+			s.scanOllama(dir)
+		}
+	}
+	if cfg.ScanLMStudio {
+		for _, dir := range LMStudioDirs(cfg.LMStudioDir) {
+			s.scanLMStudio(dir)
+		}
+	}
+
+	if scanned == 0 && firstErr != nil && len(s.models) == 0 {
+		return nil, fmt.Errorf("failed to read models directory: %w", firstErr)
+	}
 
+	s.index.prune(s.indexed)
+	if err := s.index.Save(); err != nil {
+		logger.Warn("Failed to save model index", zap.String("file", s.index.Path()), zap.Error(err))
+	}
+
+	models := pairProjectors(groupShards(s.models, logger), logger)
+	logger.Info("Discovered models", zap.Int("count", len(models)), zap.Int("parsed", s.parsed))
+	return models, nil
+}
+
+// scanner walks models directories. Symlinks are followed, but every real
+// directory is entered and every real file is listed only once, so link
+// loops and duplicate links are harmless.
+type scanner struct {
+	logger *zap.Logger
+	ignore []string
+
+	visited map[string]bool // real paths of entered directories
+	seen    map[string]bool // real paths of listed files
+	names   map[string]bool
+	models  []Model
+
+	index   *Index
+	indexed map[string]bool // paths looked up in the index
+	parsed  int             // GGUF headers read from disk
+}
+
+// scanRoot is a directory tree to scan for models
+type scanRoot struct {
+	dir   string
+	depth int
+	// prefix is prepended to the relative names of the models found
+	prefix string
+	source Source
+	// ggufOnly skips the other model formats, which in download caches
+	// are usually not llama.cpp files
+	ggufOnly bool
+	// annotate, if set, is called for every model found and may rename it
+	annotate func(*Model)
+}
+
+func (s *scanner) scanRoot(root scanRoot) error {
+	info, err := os.Stat(root.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", root.dir)
+	}
+	real, err := filepath.EvalSymlinks(root.dir)
+	if err != nil {
+		return err
+	}
+	if s.visited[real] {
+		return nil
+	}
+	s.visited[real] = true
+	return s.scanDir(root, root.dir, 0)
+}
+
+func (s *scanner) scanDir(root scanRoot, dir string, depth int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
 		name := entry.Name()
-		if !isModelFile(name) {
+		path := filepath.Join(dir, name)
+		rel, err := filepath.Rel(root.dir, path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if s.ignored(rel, name) {
 			continue
 		}
 
-		path := filepath.Join(cfg.ModelsDir, name)
-		info, err := entry.Info()
+		info, err := os.Stat(path) // follows symlinks
 		if err != nil {
-			logger.Warn("Failed to get file info", zap.String("file", name), zap.Error(err))
+			s.logger.Warn("Failed to get file info", zap.String("file", path), zap.Error(err))
 			continue
 		}
 
-		models = append(models, Model{
-			Name: name,
-			Path: path,
-			Size: info.Size(),
-		})
+		if info.IsDir() {
+			if depth >= root.depth {
+				continue
+			}
+			real, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				s.logger.Warn("Failed to resolve directory", zap.String("directory", path), zap.Error(err))
+				continue
+			}
+			if s.visited[real] {
+				s.logger.Debug("Skipping already scanned directory", zap.String("directory", path), zap.String("target", real))
+				continue
+			}
+			s.visited[real] = true
+			if err := s.scanDir(root, path, depth+1); err != nil {
+				s.logger.Warn("Failed to read directory", zap.String("directory", path), zap.Error(err))
+			}
+			continue
+		}
+
+		if !isModelFile(name) || (root.ggufOnly && !isGGUFFile(name)) {
+			continue
+		}
+		real, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			if s.seen[real] {
+				continue
+			}
+			s.seen[real] = true
+		}
 
-		logger.Debug("Found model", zap.String("name", name), zap.Int64("size", info.Size()))
+		model := Model{
+			Name:   root.prefix + rel,
+			Path:   path,
+			Dir:    root.dir,
+			Size:   info.Size(),
+			Source: root.source,
+		}
+		if root.annotate != nil {
+			root.annotate(&model)
+		}
+		model.Name = s.uniqueName(root.dir, model.Name)
+		s.describe(&model, real, info, isGGUFFile(name))
+		s.models = append(s.models, model)
+
+		s.logger.Debug("Found model", zap.String("name", model.Name), zap.Int64("size", model.Size))
 	}
+	return nil
+}
 
-	logger.Info("Discovered models", zap.Int("count", len(models)))
-	return models, nil
+// describe fills in the model's indexed facts, reading the GGUF header
+// only if the index has none for the file as it is now
+func (s *scanner) describe(model *Model, real string, info os.FileInfo, gguf bool) {
+	entry := s.index.entry(model.Path, info.Size(), info.ModTime())
+	s.indexed[model.Path] = true
+
+	if gguf && entry.GGUF == nil {
+		var err error
+		if entry.GGUF, err = ReadGGUF(model.Path); err != nil {
+			s.logger.Warn("Failed to read GGUF header", zap.String("file", model.Path), zap.Error(err))
+		} else {
+			s.index.changed()
+		}
+		s.parsed++
+	}
+	if entry.SHA256 == "" {
+		if sum := blobHash(real); sum != "" {
+			entry.SHA256 = sum
+			s.index.changed()
+		}
+	}
+
+	model.ModTime = info.ModTime()
+	model.GGUF = entry.GGUF
+	model.SHA256 = entry.SHA256
+	model.LastUsed = entry.LastUsed
+}
+
+// ignored reports whether a scan_ignore pattern matches the entry's name or
+// its path relative to the models directory
+func (s *scanner) ignored(rel, name string) bool {
+	for _, pattern := range s.ignore {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueName returns rel, prefixed with the models directory's name if
+// another directory already provided a model with that name
+func (s *scanner) uniqueName(root, rel string) string {
+	name := rel
+	if s.names[name] {
+		name = filepath.Base(root) + "/" + rel
+	}
+	for i := 2; s.names[name]; i++ {
+		name = fmt.Sprintf("%s (%d)", rel, i)
+	}
+	s.names[name] = true
+	return name
+}
+
+// ShardInfo describes a split model, e.g. "3 shards" or "2/3 shards"
+func (m Model) ShardInfo() string {
+	if m.Shards == 0 {
+		return ""
+	}
+	if len(m.MissingShards) > 0 {
+		return fmt.Sprintf("%d/%d shards", m.Shards-len(m.MissingShards), m.Shards)
+	}
+	return fmt.Sprintf("%d shards", m.Shards)
+}
+
+// MissingShardsWarning returns a warning for incomplete split models
+func (m Model) MissingShardsWarning() string {
+	if len(m.MissingShards) == 0 {
+		return ""
+	}
+	missing := make([]string, len(m.MissingShards))
+	for i, n := range m.MissingShards {
+		missing[i] = strconv.Itoa(n)
+	}
+	return fmt.Sprintf("shard %s of %d missing", strings.Join(missing, ", "), m.Shards)
 }
 
 func isModelFile(filename string) bool {
@@ -63,6 +351,10 @@ func isModelFile(filename string) bool {
 	return slices.Contains(modelExtensions, ext)
 }
 
+func isGGUFFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".gguf")
+}
+
 func GetModelNames(models []Model) []string {
 	names := make([]string, len(models))
 	for i, model := range models {
@@ -3,9 +3,12 @@ package models
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"lloader/internal/app"
 )
@@ -106,3 +109,179 @@ func TestDiscoverModels_WithFiles(t *testing.T) {
 	assert.True(t, foundNames["data.bin"])
 	assert.False(t, foundNames["test.txt"])
 }
+
+func TestDiscoverModels_ReadsGGUFHeaders(t *testing.T) {
+	tempDir := t.TempDir()
+	llamaGGUF(3).WriteFile(t, filepath.Join(tempDir, "tiny.gguf"))
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "broken.gguf"), []byte("not gguf"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "old.bin"), []byte("GGUF"), 0644))
+
+	cfg := &app.Config{
+		ModelsDir: tempDir,
+	}
+
+	models, err := DiscoverModels(cfg, zap.NewNop())
+	assert.NoError(t, err)
+	assert.Len(t, models, 3)
+
+	byName := make(map[string]Model)
+	for _, model := range models {
+		byName[model.Name] = model
+	}
+
+	if assert.NotNil(t, byName["tiny.gguf"].GGUF) {
+		assert.Equal(t, "llama", byName["tiny.gguf"].GGUF.Architecture)
+		assert.Equal(t, "Q4_K_M", byName["tiny.gguf"].GGUF.Quantization())
+	}
+	assert.Nil(t, byName["broken.gguf"].GGUF)
+	assert.Nil(t, byName["old.bin"].GGUF)
+}
+
+// writeFiles creates empty files below dir, creating parent directories
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("model"), 0644))
+	}
+}
+
+func discoveredNames(t *testing.T, cfg *app.Config) []string {
+	t.Helper()
+	models, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+	names := GetModelNames(models)
+	sort.Strings(names)
+	return names
+}
+
+func TestDiscoverModels_Recursive(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir,
+		"top.gguf",
+		"qwen/qwen3-8b/model-Q4_K_M.gguf",
+		"llama/llama-3/model-Q4_K_M.gguf",
+		"llama/llama-3/README.md",
+		"a/b/c/d/deep.gguf",
+		".cache/hidden.gguf",
+		"archive/old.gguf",
+	)
+
+	tests := []struct {
+		name   string
+		depth  int
+		ignore []string
+		want   []string
+	}{
+		{
+			name:  "top level only",
+			depth: 0,
+			want:  []string{"top.gguf"},
+		},
+		{
+			name:  "two levels",
+			depth: 2,
+			want: []string{
+				".cache/hidden.gguf",
+				"archive/old.gguf",
+				"llama/llama-3/model-Q4_K_M.gguf",
+				"qwen/qwen3-8b/model-Q4_K_M.gguf",
+				"top.gguf",
+			},
+		},
+		{
+			name:   "ignore patterns",
+			depth:  10,
+			ignore: []string{".*", "archive"},
+			want: []string{
+				"a/b/c/d/deep.gguf",
+				"llama/llama-3/model-Q4_K_M.gguf",
+				"qwen/qwen3-8b/model-Q4_K_M.gguf",
+				"top.gguf",
+			},
+		},
+		{
+			name:   "ignore by relative path",
+			depth:  10,
+			ignore: []string{".*", "*/llama-3", "a/b"},
+			want: []string{
+				"archive/old.gguf",
+				"qwen/qwen3-8b/model-Q4_K_M.gguf",
+				"top.gguf",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &app.Config{ModelsDir: dir, ScanDepth: tt.depth, ScanIgnore: tt.ignore}
+			assert.Equal(t, tt.want, discoveredNames(t, cfg))
+		})
+	}
+}
+
+func TestDiscoverModels_Symlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, "vendor/model/q4.gguf")
+
+	// A loop back to the root, a second link to the same directory and a
+	// link to a file that is already listed
+	require.NoError(t, os.Symlink(dir, filepath.Join(dir, "vendor", "loop")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "vendor", "model"), filepath.Join(dir, "alias")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "vendor", "model", "q4.gguf"), filepath.Join(dir, "vendor", "q4-link.gguf")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing.gguf"), filepath.Join(dir, "broken.gguf")))
+
+	// A symlinked directory outside the tree is followed
+	other := t.TempDir()
+	writeFiles(t, other, "external.gguf")
+	require.NoError(t, os.Symlink(other, filepath.Join(dir, "external")))
+
+	cfg := &app.Config{ModelsDir: dir, ScanDepth: 10}
+	assert.Equal(t, []string{"alias/q4.gguf", "external/external.gguf"}, discoveredNames(t, cfg))
+}
+
+func TestDiscoverModels_MultipleDirs(t *testing.T) {
+	disk1 := filepath.Join(t.TempDir(), "disk1")
+	disk2 := filepath.Join(t.TempDir(), "disk2")
+	writeFiles(t, disk1, "model-Q4_K_M.gguf", "qwen/model-Q4_K_M.gguf")
+	writeFiles(t, disk2, "model-Q4_K_M.gguf", "llama/model-Q4_K_M.gguf")
+
+	cfg := &app.Config{
+		ModelsDir:  "/ignored",
+		ModelsDirs: []string{disk1, filepath.Join(t.TempDir(), "missing"), disk2},
+		ScanDepth:  3,
+	}
+	models, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	byName := make(map[string]Model)
+	for _, model := range models {
+		byName[model.Name] = model
+	}
+	assert.Len(t, byName, 4)
+	assert.Equal(t, filepath.Join(disk1, "model-Q4_K_M.gguf"), byName["model-Q4_K_M.gguf"].Path)
+	assert.Equal(t, filepath.Join(disk2, "model-Q4_K_M.gguf"), byName["disk2/model-Q4_K_M.gguf"].Path)
+	assert.Equal(t, disk2, byName["disk2/model-Q4_K_M.gguf"].Dir)
+	assert.Contains(t, byName, "qwen/model-Q4_K_M.gguf")
+	assert.Contains(t, byName, "llama/model-Q4_K_M.gguf")
+}
+
+func TestDiscoverModels_MissingDir(t *testing.T) {
+	cfg := &app.Config{ModelsDir: filepath.Join(t.TempDir(), "missing")}
+	models, err := DiscoverModels(cfg, zap.NewNop())
+	assert.NoError(t, err)
+	assert.Empty(t, models)
+}
+
+func TestDiscoverModels_NotADir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "models")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	cfg := &app.Config{ModelsDir: file}
+	_, err := DiscoverModels(cfg, zap.NewNop())
+	assert.ErrorContains(t, err, "failed to read models directory")
+}
@@ -0,0 +1,466 @@
+package models
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// GGUF file layout (all little-endian):
+//
+//	magic "GGUF" | version u32 | tensor count | metadata kv count
+//	metadata kv: key string | value type u32 | value
+//	tensor info: name string | n_dims u32 | dims u64[n_dims] | type u32 | offset u64
+//
+// Counts and string lengths are u64 since version 2 and u32 in version 1.
+// Only the header is read; tensor data is never touched.
+
+const ggufMagic = 0x46554747 // "GGUF"
+
+// Limits that keep a corrupt header from allocating unbounded memory
+const (
+	ggufMaxStringLen = 64 << 20
+	ggufMaxCount     = 1 << 32
+	ggufMaxDims      = 8
+)
+
+type ggufType uint32
+
+const (
+	ggufUint8 ggufType = iota
+	ggufInt8
+	ggufUint16
+	ggufInt16
+	ggufUint32
+	ggufInt32
+	ggufFloat32
+	ggufBool
+	ggufString
+	ggufArray
+	ggufUint64
+	ggufInt64
+	ggufFloat64
+)
+
+// ErrNotGGUF is returned for files that do not start with the GGUF magic
+var ErrNotGGUF = errors.New("not a GGUF file")
+
+// GGUFInfo is the metadata of a GGUF file that is useful for picking and
+// launching a model
+type GGUFInfo struct {
+	Version      uint32
+	Architecture string
+	Name         string
+	// SizeLabel is the publisher's size label, e.g. "8B" or "8x7B"
+	SizeLabel string
+	// ParameterCount is summed over all tensors in this file
+	ParameterCount uint64
+	// FileType is the llama.cpp file type, or -1 if the file does not say
+	FileType       int
+	ContextLength  uint64
+	BlockCount     uint64
+	ChatTemplate   string
+	TokenizerModel string
+	TensorCount    uint64
+	// Metadata holds every scalar key/value pair; arrays are skipped
+	Metadata map[string]any
+}
+
+// Quantization returns the name of the file type, e.g. "Q4_K_M"
+func (g *GGUFInfo) Quantization() string {
+	if g.FileType < 0 {
+		return ""
+	}
+	if name, ok := ggufFileTypes[g.FileType]; ok {
+		return name
+	}
+	return fmt.Sprintf("type %d", g.FileType)
+}
+
+// Params returns a short parameter count such as "8.0B", preferring the
+// publisher's size label
+func (g *GGUFInfo) Params() string {
+	if g.SizeLabel != "" {
+		return g.SizeLabel
+	}
+	return FormatParams(g.ParameterCount)
+}
+
+// FormatParams renders a parameter count as e.g. "135M", "8.0B" or "1.2T"
+func FormatParams(n uint64) string {
+	switch {
+	case n == 0:
+		return ""
+	case n >= 1e12:
+		return fmt.Sprintf("%.1fT", float64(n)/1e12)
+	case n >= 1e9:
+		return fmt.Sprintf("%.1fB", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.0fM", float64(n)/1e6)
+	default:
+		return fmt.Sprintf("%.0fK", float64(n)/1e3)
+	}
+}
+
+// ggufFileTypes maps llama.cpp's llama_ftype values to their names
+var ggufFileTypes = map[int]string{
+	0:  "F32",
+	1:  "F16",
+	2:  "Q4_0",
+	3:  "Q4_1",
+	7:  "Q8_0",
+	8:  "Q5_0",
+	9:  "Q5_1",
+	10: "Q2_K",
+	11: "Q3_K_S",
+	12: "Q3_K_M",
+	13: "Q3_K_L",
+	14: "Q4_K_S",
+	15: "Q4_K_M",
+	16: "Q5_K_S",
+	17: "Q5_K_M",
+	18: "Q6_K",
+	19: "IQ2_XXS",
+	20: "IQ2_XS",
+	21: "Q2_K_S",
+	22: "IQ3_XS",
+	23: "IQ3_XXS",
+	24: "IQ1_S",
+	25: "IQ4_NL",
+	26: "IQ3_S",
+	27: "IQ3_M",
+	28: "IQ2_S",
+	29: "IQ2_M",
+	30: "IQ4_XS",
+	31: "IQ1_M",
+	32: "BF16",
+	36: "TQ1_0",
+	37: "TQ2_0",
+	38: "MXFP4_MOE",
+}
+
+// ReadGGUF parses the header of the GGUF file at path
+func ReadGGUF(path string) (*GGUFInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := ParseGGUF(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return info, nil
+}
+
+// ParseGGUF reads a GGUF header from r. Reading stops after the tensor
+// infos, before any tensor data.
+func ParseGGUF(r io.Reader) (*GGUFInfo, error) {
+	p := &ggufReader{r: bufio.NewReaderSize(r, 64<<10)}
+
+	magic, err := p.u32()
+	if err != nil {
+		return nil, ErrNotGGUF
+	}
+	if magic != ggufMagic {
+		return nil, ErrNotGGUF
+	}
+
+	info := &GGUFInfo{FileType: -1, Metadata: make(map[string]any)}
+	if info.Version, err = p.u32(); err != nil {
+		return nil, err
+	}
+	if info.Version < 1 || info.Version > 3 {
+		return nil, fmt.Errorf("unsupported GGUF version %d", info.Version)
+	}
+	p.v1 = info.Version == 1
+
+	if info.TensorCount, err = p.count(); err != nil {
+		return nil, err
+	}
+	kvCount, err := p.count()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := uint64(0); i < kvCount; i++ {
+		key, err := p.str()
+		if err != nil {
+			return nil, fmt.Errorf("metadata key %d: %w", i, err)
+		}
+		typ, err := p.u32()
+		if err != nil {
+			return nil, fmt.Errorf("metadata %s: %w", key, err)
+		}
+		// Arrays such as the tokenizer vocabulary are skipped
+		value, err := p.value(ggufType(typ))
+		if err != nil {
+			return nil, fmt.Errorf("metadata %s: %w", key, err)
+		}
+		if value != nil {
+			info.Metadata[key] = value
+		}
+	}
+
+	for i := uint64(0); i < info.TensorCount; i++ {
+		n, err := p.tensorElements()
+		if err != nil {
+			return nil, fmt.Errorf("tensor info %d: %w", i, err)
+		}
+		info.ParameterCount += n
+	}
+
+	info.fill()
+	return info, nil
+}
+
+// fill copies the well-known keys out of Metadata
+func (g *GGUFInfo) fill() {
+	g.Architecture = g.metaString("general.architecture")
+	g.Name = g.metaString("general.name")
+	g.SizeLabel = g.metaString("general.size_label")
+	g.ChatTemplate = g.metaString("tokenizer.chat_template")
+	g.TokenizerModel = g.metaString("tokenizer.ggml.model")
+	if v, ok := g.metaUint("general.file_type"); ok {
+		g.FileType = int(v)
+	}
+	if g.Architecture != "" {
+		g.ContextLength, _ = g.metaUint(g.Architecture + ".context_length")
+		g.BlockCount, _ = g.metaUint(g.Architecture + ".block_count")
+	}
+}
+
+func (g *GGUFInfo) metaString(key string) string {
+	s, _ := g.Metadata[key].(string)
+	return s
+}
+
+// metaUint returns an integer value regardless of its stored width
+func (g *GGUFInfo) metaUint(key string) (uint64, bool) {
+	switch v := g.Metadata[key].(type) {
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case int8:
+		return uint64(max(v, 0)), true
+	case int16:
+		return uint64(max(v, 0)), true
+	case int32:
+		return uint64(max(v, 0)), true
+	case int64:
+		return uint64(max(v, 0)), true
+	}
+	return 0, false
+}
+
+type ggufReader struct {
+	r   *bufio.Reader
+	v1  bool
+	buf [8]byte
+}
+
+func (p *ggufReader) read(n int) ([]byte, error) {
+	if _, err := io.ReadFull(p.r, p.buf[:n]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return p.buf[:n], nil
+}
+
+func (p *ggufReader) u32() (uint32, error) {
+	b, err := p.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (p *ggufReader) u64() (uint64, error) {
+	b, err := p.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// count reads a count or length field, which is 32 bits wide in version 1
+func (p *ggufReader) count() (uint64, error) {
+	var n uint64
+	var err error
+	if p.v1 {
+		var n32 uint32
+		n32, err = p.u32()
+		n = uint64(n32)
+	} else {
+		n, err = p.u64()
+	}
+	if err != nil {
+		return 0, err
+	}
+	if n > ggufMaxCount {
+		return 0, fmt.Errorf("count %d is implausibly large", n)
+	}
+	return n, nil
+}
+
+func (p *ggufReader) str() (string, error) {
+	n, err := p.count()
+	if err != nil {
+		return "", err
+	}
+	if n > ggufMaxStringLen {
+		return "", fmt.Errorf("string of %d bytes is too long", n)
+	}
+	var sb strings.Builder
+	sb.Grow(int(n))
+	if _, err := io.CopyN(&sb, p.r, int64(n)); err != nil {
+		return "", io.ErrUnexpectedEOF
+	}
+	return sb.String(), nil
+}
+
+func (p *ggufReader) skip(n uint64) error {
+	for n > 0 {
+		chunk := min(n, math.MaxInt32)
+		if _, err := p.r.Discard(int(chunk)); err != nil {
+			return io.ErrUnexpectedEOF
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// scalarSize returns the encoded size of fixed-width types
+func scalarSize(t ggufType) (uint64, bool) {
+	switch t {
+	case ggufUint8, ggufInt8, ggufBool:
+		return 1, true
+	case ggufUint16, ggufInt16:
+		return 2, true
+	case ggufUint32, ggufInt32, ggufFloat32:
+		return 4, true
+	case ggufUint64, ggufInt64, ggufFloat64:
+		return 8, true
+	}
+	return 0, false
+}
+
+// value decodes a metadata value. Arrays are skipped and yield nil.
+func (p *ggufReader) value(t ggufType) (any, error) {
+	switch t {
+	case ggufString:
+		return p.str()
+	case ggufArray:
+		return nil, p.skipArray()
+	}
+
+	size, ok := scalarSize(t)
+	if !ok {
+		return nil, fmt.Errorf("unknown value type %d", t)
+	}
+	b, err := p.read(int(size))
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case ggufUint8:
+		return b[0], nil
+	case ggufInt8:
+		return int8(b[0]), nil
+	case ggufBool:
+		return b[0] != 0, nil
+	case ggufUint16:
+		return binary.LittleEndian.Uint16(b), nil
+	case ggufInt16:
+		return int16(binary.LittleEndian.Uint16(b)), nil
+	case ggufUint32:
+		return binary.LittleEndian.Uint32(b), nil
+	case ggufInt32:
+		return int32(binary.LittleEndian.Uint32(b)), nil
+	case ggufFloat32:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+	case ggufUint64:
+		return binary.LittleEndian.Uint64(b), nil
+	case ggufInt64:
+		return int64(binary.LittleEndian.Uint64(b)), nil
+	default: // ggufFloat64
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	}
+}
+
+func (p *ggufReader) skipArray() error {
+	typ, err := p.u32()
+	if err != nil {
+		return err
+	}
+	n, err := p.count()
+	if err != nil {
+		return err
+	}
+	t := ggufType(typ)
+	if size, ok := scalarSize(t); ok {
+		return p.skip(size * n)
+	}
+	for i := uint64(0); i < n; i++ {
+		switch t {
+		case ggufString:
+			l, err := p.count()
+			if err != nil {
+				return err
+			}
+			if err := p.skip(l); err != nil {
+				return err
+			}
+		case ggufArray:
+			if err := p.skipArray(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown array element type %d", t)
+		}
+	}
+	return nil
+}
+
+// tensorElements reads one tensor info and returns its element count
+func (p *ggufReader) tensorElements() (uint64, error) {
+	if _, err := p.str(); err != nil {
+		return 0, err
+	}
+	dims, err := p.u32()
+	if err != nil {
+		return 0, err
+	}
+	if dims > ggufMaxDims {
+		return 0, fmt.Errorf("tensor has %d dimensions", dims)
+	}
+	n := uint64(1)
+	for d := uint32(0); d < dims; d++ {
+		dim, err := p.count()
+		if err != nil {
+			return 0, err
+		}
+		n *= dim
+	}
+	// type and data offset
+	if _, err := p.u32(); err != nil {
+		return 0, err
+	}
+	if _, err := p.u64(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,249 @@
+package models
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ggufBuilder writes synthetic GGUF headers for tests
+type ggufBuilder struct {
+	buf     bytes.Buffer
+	version uint32
+	kvs     [][]byte
+	tensors [][]byte
+}
+
+func newGGUF(version uint32) *ggufBuilder {
+	return &ggufBuilder{version: version}
+}
+
+func (b *ggufBuilder) count(w *bytes.Buffer, n uint64) {
+	if b.version == 1 {
+		binary.Write(w, binary.LittleEndian, uint32(n))
+	} else {
+		binary.Write(w, binary.LittleEndian, n)
+	}
+}
+
+func (b *ggufBuilder) str(w *bytes.Buffer, s string) {
+	b.count(w, uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (b *ggufBuilder) kv(key string, typ ggufType, value func(w *bytes.Buffer)) *ggufBuilder {
+	var w bytes.Buffer
+	b.str(&w, key)
+	binary.Write(&w, binary.LittleEndian, uint32(typ))
+	value(&w)
+	b.kvs = append(b.kvs, w.Bytes())
+	return b
+}
+
+func (b *ggufBuilder) String(key, value string) *ggufBuilder {
+	return b.kv(key, ggufString, func(w *bytes.Buffer) { b.str(w, value) })
+}
+
+func (b *ggufBuilder) Uint32(key string, value uint32) *ggufBuilder {
+	return b.kv(key, ggufUint32, func(w *bytes.Buffer) { binary.Write(w, binary.LittleEndian, value) })
+}
+
+func (b *ggufBuilder) Uint64(key string, value uint64) *ggufBuilder {
+	return b.kv(key, ggufUint64, func(w *bytes.Buffer) { binary.Write(w, binary.LittleEndian, value) })
+}
+
+func (b *ggufBuilder) Float32(key string, value float32) *ggufBuilder {
+	return b.kv(key, ggufFloat32, func(w *bytes.Buffer) { binary.Write(w, binary.LittleEndian, math.Float32bits(value)) })
+}
+
+func (b *ggufBuilder) Bool(key string, value bool) *ggufBuilder {
+	return b.kv(key, ggufBool, func(w *bytes.Buffer) {
+		if value {
+			w.WriteByte(1)
+		} else {
+			w.WriteByte(0)
+		}
+	})
+}
+
+func (b *ggufBuilder) Strings(key string, values ...string) *ggufBuilder {
+	return b.kv(key, ggufArray, func(w *bytes.Buffer) {
+		binary.Write(w, binary.LittleEndian, uint32(ggufString))
+		b.count(w, uint64(len(values)))
+		for _, v := range values {
+			b.str(w, v)
+		}
+	})
+}
+
+func (b *ggufBuilder) Floats(key string, values ...float32) *ggufBuilder {
+	return b.kv(key, ggufArray, func(w *bytes.Buffer) {
+		binary.Write(w, binary.LittleEndian, uint32(ggufFloat32))
+		b.count(w, uint64(len(values)))
+		for _, v := range values {
+			binary.Write(w, binary.LittleEndian, math.Float32bits(v))
+		}
+	})
+}
+
+func (b *ggufBuilder) Tensor(name string, dims ...uint64) *ggufBuilder {
+	var w bytes.Buffer
+	b.str(&w, name)
+	binary.Write(&w, binary.LittleEndian, uint32(len(dims)))
+	for _, d := range dims {
+		b.count(&w, d)
+	}
+	binary.Write(&w, binary.LittleEndian, uint32(0))
+	binary.Write(&w, binary.LittleEndian, uint64(0))
+	b.tensors = append(b.tensors, w.Bytes())
+	return b
+}
+
+func (b *ggufBuilder) Bytes() []byte {
+	var w bytes.Buffer
+	w.WriteString("GGUF")
+	binary.Write(&w, binary.LittleEndian, b.version)
+	b.count(&w, uint64(len(b.tensors)))
+	b.count(&w, uint64(len(b.kvs)))
+	for _, kv := range b.kvs {
+		w.Write(kv)
+	}
+	for _, t := range b.tensors {
+		w.Write(t)
+	}
+	// Stand-in for tensor data, which must never be read
+	w.WriteString("tensor data")
+	return w.Bytes()
+}
+
+func (b *ggufBuilder) WriteFile(t *testing.T, path string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, b.Bytes(), 0644))
+}
+
+func llamaGGUF(version uint32) *ggufBuilder {
+	return newGGUF(version).
+		String("general.architecture", "llama").
+		String("general.name", "Tiny Llama").
+		Uint32("general.file_type", 15).
+		Uint64("llama.context_length", 8192).
+		Uint32("llama.block_count", 32).
+		Float32("llama.rope.freq_base", 500000).
+		String("tokenizer.ggml.model", "gpt2").
+		Strings("tokenizer.ggml.tokens", "<s>", "</s>", "hello", "world").
+		Floats("tokenizer.ggml.scores", 0, 0, -1.5, -2).
+		Bool("tokenizer.ggml.add_bos_token", true).
+		String("tokenizer.chat_template", "{% for m in messages %}{{ m.content }}{% endfor %}").
+		Tensor("token_embd.weight", 4096, 128256).
+		Tensor("blk.0.attn_norm.weight", 4096)
+}
+
+func TestParseGGUF(t *testing.T) {
+	for _, version := range []uint32{1, 2, 3} {
+		t.Run("version "+string(rune('0'+version)), func(t *testing.T) {
+			info, err := ParseGGUF(bytes.NewReader(llamaGGUF(version).Bytes()))
+			require.NoError(t, err)
+
+			assert.Equal(t, version, info.Version)
+			assert.Equal(t, "llama", info.Architecture)
+			assert.Equal(t, "Tiny Llama", info.Name)
+			assert.Equal(t, 15, info.FileType)
+			assert.Equal(t, "Q4_K_M", info.Quantization())
+			assert.Equal(t, uint64(8192), info.ContextLength)
+			assert.Equal(t, uint64(32), info.BlockCount)
+			assert.Equal(t, "gpt2", info.TokenizerModel)
+			assert.Contains(t, info.ChatTemplate, "messages")
+			assert.Equal(t, uint64(2), info.TensorCount)
+			assert.Equal(t, uint64(4096*128256+4096), info.ParameterCount)
+			assert.Equal(t, "525M", info.Params())
+
+			assert.Equal(t, true, info.Metadata["tokenizer.ggml.add_bos_token"])
+			assert.Equal(t, float32(500000), info.Metadata["llama.rope.freq_base"])
+			assert.NotContains(t, info.Metadata, "tokenizer.ggml.tokens")
+		})
+	}
+}
+
+func TestParseGGUF_SizeLabel(t *testing.T) {
+	data := newGGUF(3).
+		String("general.architecture", "qwen3").
+		String("general.size_label", "8B").
+		Uint64("qwen3.context_length", 40960).
+		Bytes()
+
+	info, err := ParseGGUF(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "8B", info.Params())
+	assert.Equal(t, uint64(40960), info.ContextLength)
+	assert.Equal(t, -1, info.FileType)
+	assert.Equal(t, "", info.Quantization())
+}
+
+func TestParseGGUF_Errors(t *testing.T) {
+	valid := llamaGGUF(3).Bytes()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "empty", data: nil, wantErr: ErrNotGGUF.Error()},
+		{name: "wrong magic", data: []byte("GGML\x03\x00\x00\x00"), wantErr: ErrNotGGUF.Error()},
+		{name: "unsupported version", data: []byte("GGUF\x09\x00\x00\x00"), wantErr: "unsupported GGUF version 9"},
+		{name: "truncated metadata", data: valid[:200], wantErr: "unexpected EOF"},
+		{
+			name:    "unknown value type",
+			data:    newGGUF(3).kv("bad", ggufType(42), func(*bytes.Buffer) {}).Bytes(),
+			wantErr: "unknown value type 42",
+		},
+		{
+			name: "huge string",
+			data: newGGUF(3).kv("big", ggufString, func(w *bytes.Buffer) {
+				binary.Write(w, binary.LittleEndian, uint64(1<<40))
+			}).Bytes(),
+			wantErr: "implausibly large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGGUF(bytes.NewReader(tt.data))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReadGGUF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tiny.gguf")
+	llamaGGUF(3).WriteFile(t, path)
+
+	info, err := ReadGGUF(path)
+	require.NoError(t, err)
+	assert.Equal(t, "llama", info.Architecture)
+
+	_, err = ReadGGUF(filepath.Join(t.TempDir(), "missing.gguf"))
+	assert.Error(t, err)
+}
+
+func TestFormatParams(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{0, ""},
+		{135_000_000, "135M"},
+		{7_241_732_096, "7.2B"},
+		{1_200_000_000_000, "1.2T"},
+		{500_000, "500K"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatParams(tt.n))
+	}
+}
@@ -0,0 +1,302 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// indexVersion is bumped whenever the stored metadata changes shape; older
+// indexes are then discarded and rebuilt
+const indexVersion = 1
+
+// Index is the on-disk cache of per-file facts. Entries are keyed by path
+// and are valid as long as the file's size and modification time match;
+// a changed file loses its metadata and hash but keeps its annotations.
+//
+// A nil *Index is valid and caches nothing.
+type Index struct {
+	path    string
+	entries map[string]*IndexEntry
+	dirty   bool
+}
+
+// IndexEntry is what the index knows about one file
+type IndexEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	// GGUF is the parsed header. Values in GGUF.Metadata come back from
+	// JSON as float64, bool or string.
+	GGUF   *GGUFInfo `json:"gguf,omitempty"`
+	SHA256 string    `json:"sha256,omitempty"`
+
+	// Annotations, kept when the file changes
+	LastUsed time.Time `json:"last_used,omitzero"`
+}
+
+// indexMu serializes load-modify-save cycles of the index within the
+// process, e.g. a background rescan and a launch being recorded
+var indexMu sync.Mutex
+
+type indexFile struct {
+	Version int                    `json:"version"`
+	Entries map[string]*IndexEntry `json:"entries"`
+}
+
+// OpenIndex loads the index at path. A missing file gives an empty index.
+// An unreadable or outdated one also gives an empty index, which replaces
+// the file on the next Save, and for unreadable files an error.
+func OpenIndex(path string) (*Index, error) {
+	ix := &Index{path: path, entries: make(map[string]*IndexEntry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return ix, err
+	}
+
+	var file indexFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		ix.dirty = true
+		return ix, fmt.Errorf("corrupt index %s: %w", path, err)
+	}
+	if file.Version != indexVersion {
+		ix.dirty = true
+		return ix, nil
+	}
+	for path, entry := range file.Entries {
+		if entry != nil {
+			ix.entries[path] = entry
+		}
+	}
+	return ix, nil
+}
+
+// Path returns the file the index is stored in
+func (ix *Index) Path() string {
+	if ix == nil {
+		return ""
+	}
+	return ix.path
+}
+
+// Len returns the number of indexed files
+func (ix *Index) Len() int {
+	if ix == nil {
+		return 0
+	}
+	return len(ix.entries)
+}
+
+// Lookup returns the entry for path if it is still valid for a file of the
+// given size and modification time
+func (ix *Index) Lookup(path string, size int64, modTime time.Time) (*IndexEntry, bool) {
+	if ix == nil {
+		return nil, false
+	}
+	entry, ok := ix.entries[path]
+	if !ok || entry.Size != size || !entry.ModTime.Equal(modTime) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// entry returns the entry for path, creating it or invalidating its cached
+// facts if the file changed
+func (ix *Index) entry(path string, size int64, modTime time.Time) *IndexEntry {
+	if ix == nil {
+		return &IndexEntry{Size: size, ModTime: modTime}
+	}
+	if entry, ok := ix.Lookup(path, size, modTime); ok {
+		return entry
+	}
+	entry := &IndexEntry{Size: size, ModTime: modTime}
+	if old, ok := ix.entries[path]; ok {
+		entry.LastUsed = old.LastUsed
+	}
+	ix.entries[path] = entry
+	ix.dirty = true
+	return entry
+}
+
+// changed marks the index for saving after an entry was filled in
+func (ix *Index) changed() {
+	if ix != nil {
+		ix.dirty = true
+	}
+}
+
+// MarkUsed records that the model at path was launched
+func (ix *Index) MarkUsed(path string, t time.Time) {
+	if ix == nil {
+		return
+	}
+	entry, ok := ix.entries[path]
+	if !ok {
+		info, err := os.Stat(path)
+		if err != nil {
+			return
+		}
+		entry = ix.entry(path, info.Size(), info.ModTime())
+	}
+	entry.LastUsed = t
+	ix.dirty = true
+}
+
+// Unhashed returns the indexed paths without a checksum, sorted
+func (ix *Index) Unhashed() []string {
+	if ix == nil {
+		return nil
+	}
+	var paths []string
+	for path, entry := range ix.entries {
+		if entry.SHA256 == "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Hash computes and stores the SHA-256 of the file at path. Size and
+// modification time are taken before hashing, so if the file changes
+// meanwhile the next scan drops the checksum again.
+func (ix *Index) Hash(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	sum, err := HashFile(path)
+	if err != nil {
+		return "", err
+	}
+	ix.entry(path, info.Size(), info.ModTime()).SHA256 = sum
+	ix.changed()
+	return sum, nil
+}
+
+// prune drops entries that were not seen by the last scan and whose files
+// are gone. Entries of files that merely were not scanned, e.g. on an
+// unmounted share, are kept along with their annotations.
+func (ix *Index) prune(seen map[string]bool) {
+	if ix == nil {
+		return
+	}
+	for path := range ix.entries {
+		if seen[path] {
+			continue
+		}
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			delete(ix.entries, path)
+			ix.dirty = true
+		}
+	}
+}
+
+// Save writes the index if it changed. The file is replaced atomically so
+// a concurrent lload never reads a partial index.
+func (ix *Index) Save() error {
+	if ix == nil || !ix.dirty {
+		return nil
+	}
+	data, err := json.Marshal(indexFile{Version: indexVersion, Entries: ix.entries})
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(ix.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".index-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), ix.path); err != nil {
+		return err
+	}
+	ix.dirty = false
+	return nil
+}
+
+// MarkUsed records in the index at indexPath that the model at path was
+// launched. It does nothing if the index is disabled.
+func MarkUsed(indexPath, path string) error {
+	if indexPath == "" {
+		return nil
+	}
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	ix, _ := OpenIndex(indexPath)
+	ix.MarkUsed(path, time.Now())
+	return ix.Save()
+}
+
+// ClearIndex drops all cached metadata and checksums from the index at
+// path, so the next scan reads every file again. Annotations are kept.
+func ClearIndex(path string) error {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	ix, _ := OpenIndex(path)
+	for path, entry := range ix.entries {
+		if entry.LastUsed.IsZero() {
+			delete(ix.entries, path)
+			continue
+		}
+		ix.entries[path] = &IndexEntry{LastUsed: entry.LastUsed}
+	}
+	ix.dirty = true
+	return ix.Save()
+}
+
+// HashFile returns the hex SHA-256 of the file at path
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+var blobHashPattern = regexp.MustCompile(`^(?:sha256[-:])?([0-9a-f]{64})$`)
+
+// blobHash returns the checksum that content-addressed stores put in the
+// file name: huggingface_hub's blobs/<sha256> and Ollama's
+// blobs/sha256-<sha256>
+func blobHash(real string) string {
+	if filepath.Base(filepath.Dir(real)) != "blobs" {
+		return ""
+	}
+	if m := blobHashPattern.FindStringSubmatch(filepath.Base(real)); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+)
+
+func indexedConfig(t *testing.T) *app.Config {
+	t.Helper()
+	return &app.Config{
+		ModelsDir: t.TempDir(),
+		ScanDepth: 5,
+		IndexFile: filepath.Join(t.TempDir(), "lloader", "index.json"),
+	}
+}
+
+func discoverOne(t *testing.T, cfg *app.Config) Model {
+	t.Helper()
+	found, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	return found[0]
+}
+
+func TestDiscoverModels_Index(t *testing.T) {
+	cfg := indexedConfig(t)
+	path := filepath.Join(cfg.ModelsDir, "model.gguf")
+	llamaGGUF(3).WriteFile(t, path)
+	mtime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+
+	model := discoverOne(t, cfg)
+	require.NotNil(t, model.GGUF)
+	assert.Equal(t, "llama", model.GGUF.Architecture)
+	assert.FileExists(t, cfg.IndexFile)
+
+	// Same size and mtime: the header comes from the index, not the file
+	other := newGGUF(3).String("general.architecture", "qwen3").Bytes()
+	data := append(other, make([]byte, len(llamaGGUF(3).Bytes())-len(other))...)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+
+	model = discoverOne(t, cfg)
+	require.NotNil(t, model.GGUF)
+	assert.Equal(t, "llama", model.GGUF.Architecture)
+	assert.Equal(t, "Q4_K_M", model.GGUF.Quantization())
+	assert.Equal(t, uint64(8192), model.GGUF.ContextLength)
+
+	// A new mtime invalidates the entry
+	require.NoError(t, os.Chtimes(path, mtime.Add(time.Hour), mtime.Add(time.Hour)))
+	model = discoverOne(t, cfg)
+	require.NotNil(t, model.GGUF)
+	assert.Equal(t, "qwen3", model.GGUF.Architecture)
+
+	// So does a new size, even with the old mtime
+	llamaGGUF(1).WriteFile(t, path)
+	require.NoError(t, os.Chtimes(path, mtime.Add(time.Hour), mtime.Add(time.Hour)))
+	model = discoverOne(t, cfg)
+	require.NotNil(t, model.GGUF)
+	assert.Equal(t, "llama", model.GGUF.Architecture)
+	assert.Equal(t, uint32(1), model.GGUF.Version)
+}
+
+func TestDiscoverModels_IndexKeepsAnnotations(t *testing.T) {
+	cfg := indexedConfig(t)
+	path := filepath.Join(cfg.ModelsDir, "model.gguf")
+	llamaGGUF(3).WriteFile(t, path)
+	discoverOne(t, cfg)
+
+	require.NoError(t, MarkUsed(cfg.IndexFile, path))
+	used := discoverOne(t, cfg).LastUsed
+	assert.WithinDuration(t, time.Now(), used, time.Minute)
+
+	// Replacing the file drops the metadata but not the launch history
+	llamaGGUF(1).WriteFile(t, path)
+	model := discoverOne(t, cfg)
+	assert.Equal(t, uint32(1), model.GGUF.Version)
+	assert.True(t, used.Equal(model.LastUsed))
+
+	// Rebuilding also keeps it
+	require.NoError(t, ClearIndex(cfg.IndexFile))
+	ix, err := OpenIndex(cfg.IndexFile)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	_, ok := ix.Lookup(path, info.Size(), info.ModTime())
+	assert.False(t, ok, "rebuilding invalidates every entry")
+	assert.True(t, used.Equal(discoverOne(t, cfg).LastUsed))
+}
+
+func TestDiscoverModels_IndexPrunesDeletedFiles(t *testing.T) {
+	cfg := indexedConfig(t)
+	keep := filepath.Join(cfg.ModelsDir, "keep.gguf")
+	gone := filepath.Join(cfg.ModelsDir, "gone.gguf")
+	llamaGGUF(3).WriteFile(t, keep)
+	llamaGGUF(3).WriteFile(t, gone)
+
+	_, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+	ix, err := OpenIndex(cfg.IndexFile)
+	require.NoError(t, err)
+	assert.Equal(t, 2, ix.Len())
+
+	require.NoError(t, os.Remove(gone))
+	discoverOne(t, cfg)
+	ix, err = OpenIndex(cfg.IndexFile)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ix.Len())
+}
+
+func TestOpenIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	ix, err := OpenIndex(filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, ix.Len())
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	require.NoError(t, os.WriteFile(corrupt, []byte("{not json"), 0644))
+	ix, err = OpenIndex(corrupt)
+	assert.ErrorContains(t, err, "corrupt index")
+	require.NotNil(t, ix)
+	assert.Equal(t, 0, ix.Len())
+	require.NoError(t, ix.Save())
+	_, err = OpenIndex(corrupt)
+	assert.NoError(t, err, "saving replaces a corrupt index")
+
+	outdated := filepath.Join(dir, "outdated.json")
+	require.NoError(t, os.WriteFile(outdated, []byte(`{"version":0,"entries":{"/m.gguf":{"size":1}}}`), 0644))
+	ix, err = OpenIndex(outdated)
+	require.NoError(t, err)
+	assert.Equal(t, 0, ix.Len())
+}
+
+func TestIndex_Hash(t *testing.T) {
+	cfg := indexedConfig(t)
+	path := filepath.Join(cfg.ModelsDir, "model.gguf")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0644))
+	discoverOne(t, cfg)
+
+	ix, err := OpenIndex(cfg.IndexFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, ix.Unhashed())
+
+	sum, err := ix.Hash(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", sum)
+	require.NoError(t, ix.Save())
+
+	assert.Equal(t, sum, discoverOne(t, cfg).SHA256)
+	ix, err = OpenIndex(cfg.IndexFile)
+	require.NoError(t, err)
+	assert.Empty(t, ix.Unhashed())
+}
+
+func TestBlobHash(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/hub/models--org--repo/blobs/" + sum, sum},
+		{"/ollama/models/blobs/sha256-" + sum, sum},
+		{"/models/" + sum, ""},
+		{"/hub/models--org--repo/blobs/" + sum[:40], ""},
+		{"/models/blobs/model.gguf", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, blobHash(tt.path), tt.path)
+	}
+}
+
+func TestIndex_Nil(t *testing.T) {
+	var ix *Index
+	_, ok := ix.Lookup("/m.gguf", 1, time.Time{})
+	assert.False(t, ok)
+	ix.MarkUsed("/m.gguf", time.Now())
+	assert.NoError(t, ix.Save())
+	assert.Equal(t, 0, ix.Len())
+	assert.NoError(t, MarkUsed("", "/m.gguf"))
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// LMStudioDirs returns the LM Studio model directories: dir if set,
+// otherwise the current and the pre-0.3 default locations
+func LMStudioDirs(dir string) []string {
+	if dir != "" {
+		return []string{dir}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(home, ".lmstudio", "models"),
+		filepath.Join(home, ".cache", "lm-studio", "models"),
+	}
+}
+
+// scanLMStudio adds the GGUF files of an LM Studio models directory, which
+// is laid out as <publisher>/<model>/<file>.gguf
+func (s *scanner) scanLMStudio(dir string) {
+	err := s.scanRoot(scanRoot{
+		dir:      dir,
+		depth:    2,
+		source:   SourceLMStudio,
+		ggufOnly: true,
+	})
+	if err != nil {
+		s.logger.Debug("Skipping LM Studio directory", zap.String("directory", dir), zap.Error(err))
+	}
+}
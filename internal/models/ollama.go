@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	ollamaRegistry       = "registry.ollama.ai"
+	ollamaModelLayer     = "application/vnd.ollama.image.model"
+	ollamaProjectorLayer = "application/vnd.ollama.image.projector"
+)
+
+// OllamaDirs returns the Ollama model stores to look at: $OLLAMA_MODELS, or
+// the per-user store and the one used by the Linux system service
+func OllamaDirs() []string {
+	if dir := os.Getenv("OLLAMA_MODELS"); dir != "" {
+		return []string{dir}
+	}
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".ollama", "models"))
+	}
+	if runtime.GOOS == "linux" {
+		dirs = append(dirs, "/usr/share/ollama/.ollama/models")
+	}
+	return dirs
+}
+
+// ollamaManifest is the OCI-style manifest Ollama keeps per model tag
+type ollamaManifest struct {
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"layers"`
+}
+
+// ollamaName turns a manifest path below manifests/ into the name Ollama
+// shows, e.g. registry.ollama.ai/library/llama3.2/3b becomes llama3.2:3b
+func ollamaName(rel string) string {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 2 {
+		return rel
+	}
+	name := strings.Join(parts[:len(parts)-1], "/") + ":" + parts[len(parts)-1]
+	name = strings.TrimPrefix(name, ollamaRegistry+"/")
+	return strings.TrimPrefix(name, "library/")
+}
+
+// ollamaBlob returns the path of the blob with the given digest
+func ollamaBlob(root, digest string) string {
+	return filepath.Join(root, "blobs", strings.Replace(digest, ":", "-", 1))
+}
+
+// scanOllama adds the GGUF blob of every model tag in an Ollama store. The
+// blobs are used in place under their friendly name:tag.
+func (s *scanner) scanOllama(root string) {
+	manifests := filepath.Join(root, "manifests")
+	if _, err := os.Stat(manifests); err != nil {
+		s.logger.Debug("Skipping Ollama store", zap.String("directory", root), zap.Error(err))
+		return
+	}
+
+	filepath.WalkDir(manifests, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(manifests, path)
+		if err != nil {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		var manifest ollamaManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			s.logger.Debug("Skipping unreadable Ollama manifest", zap.String("file", path), zap.Error(err))
+			return nil
+		}
+
+		var model, projector string
+		for _, layer := range manifest.Layers {
+			switch layer.MediaType {
+			case ollamaModelLayer:
+				model = ollamaBlob(root, layer.Digest)
+			case ollamaProjectorLayer:
+				projector = ollamaBlob(root, layer.Digest)
+			}
+		}
+		if model != "" {
+			s.addOllamaModel(root, ollamaName(rel), model, projector)
+		}
+		return nil
+	})
+}
+
+func (s *scanner) addOllamaModel(root, name, blob, projector string) {
+	info, err := os.Stat(blob)
+	if err != nil {
+		s.logger.Warn("Ollama model blob is missing", zap.String("name", name), zap.String("blob", blob))
+		return
+	}
+	// Several tags may point at the same blob
+	real, err := filepath.EvalSymlinks(blob)
+	if err == nil {
+		if s.seen[real] {
+			return
+		}
+		s.seen[real] = true
+	}
+
+	model := Model{
+		Name:   s.uniqueName(root, name),
+		Path:   blob,
+		Dir:    root,
+		Size:   info.Size(),
+		Source: SourceOllama,
+	}
+	s.describe(&model, real, info, true)
+	if projector != "" {
+		if info, err := os.Stat(projector); err == nil {
+			model.Projector = &Model{Name: name + " projector", Path: projector, Dir: root, Size: info.Size(), Source: SourceOllama}
+		}
+	}
+	s.models = append(s.models, model)
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+)
+
+func TestOllamaName(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want string
+	}{
+		{"registry.ollama.ai/library/llama3.2/3b", "llama3.2:3b"},
+		{"registry.ollama.ai/jmorgan/tiny/latest", "jmorgan/tiny:latest"},
+		{"hf.co/bartowski/Qwen3-8B-GGUF/Q4_K_M", "hf.co/bartowski/Qwen3-8B-GGUF:Q4_K_M"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			assert.Equal(t, tt.want, ollamaName(tt.rel))
+		})
+	}
+}
+
+// writeOllamaManifest stores a manifest for name (registry/namespace/model/tag)
+func writeOllamaManifest(t *testing.T, root, name string, layers map[string]string) {
+	t.Helper()
+	var manifest struct {
+		SchemaVersion int              `json:"schemaVersion"`
+		Layers        []map[string]any `json:"layers"`
+	}
+	manifest.SchemaVersion = 2
+	for mediaType, digest := range layers {
+		manifest.Layers = append(manifest.Layers, map[string]any{"mediaType": mediaType, "digest": digest, "size": 1})
+	}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	path := filepath.Join(root, "manifests", filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestDiscoverModels_Ollama(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("OLLAMA_MODELS", root)
+
+	llamaGGUF(3).WriteFile(t, filepath.Join(root, "blobs", "sha256-aaa"))
+	writeFiles(t, root, "blobs/sha256-bbb", "blobs/sha256-ccc", "blobs/sha256-template")
+
+	writeOllamaManifest(t, root, "registry.ollama.ai/library/llama3.2/3b", map[string]string{
+		ollamaModelLayer:                        "sha256:aaa",
+		"application/vnd.ollama.image.template": "sha256:template",
+	})
+	// Another tag of the same blob is listed once
+	writeOllamaManifest(t, root, "registry.ollama.ai/library/llama3.2/latest", map[string]string{
+		ollamaModelLayer: "sha256:aaa",
+	})
+	writeOllamaManifest(t, root, "registry.ollama.ai/library/llava/7b", map[string]string{
+		ollamaModelLayer:     "sha256:bbb",
+		ollamaProjectorLayer: "sha256:ccc",
+	})
+	writeOllamaManifest(t, root, "registry.ollama.ai/library/gone/latest", map[string]string{
+		ollamaModelLayer: "sha256:missing",
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(root, "manifests", "garbage"), []byte("{"), 0644))
+
+	cfg := &app.Config{ModelsDir: t.TempDir(), ScanOllama: true}
+	found, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+
+	llama := found[0]
+	assert.Equal(t, "llama3.2:3b", llama.Name)
+	assert.Equal(t, SourceOllama, llama.Source)
+	assert.Equal(t, filepath.Join(root, "blobs", "sha256-aaa"), llama.Path)
+	require.NotNil(t, llama.GGUF)
+	assert.Equal(t, "llama", llama.GGUF.Architecture)
+
+	llava := found[1]
+	assert.Equal(t, "llava:7b", llava.Name)
+	require.NotNil(t, llava.Projector)
+	assert.Equal(t, filepath.Join(root, "blobs", "sha256-ccc"), llava.Projector.Path)
+}
+
+func TestDiscoverModels_LMStudio(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir,
+		"lmstudio-community/gemma-3-4b-it-GGUF/gemma-3-4b-it-Q4_K_M.gguf",
+		"lmstudio-community/gemma-3-4b-it-GGUF/mmproj-model-f16.gguf",
+		"lmstudio-community/gemma-3-4b-it-GGUF/README.md",
+		"bartowski/Qwen3-8B-GGUF/Qwen3-8B-Q6_K.gguf",
+		"bartowski/Qwen3-8B-GGUF/nested/too/deep.gguf",
+		"other/model.bin",
+	)
+
+	cfg := &app.Config{ModelsDir: t.TempDir(), ScanLMStudio: true, LMStudioDir: dir}
+	found, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"bartowski/Qwen3-8B-GGUF/Qwen3-8B-Q6_K.gguf",
+		"lmstudio-community/gemma-3-4b-it-GGUF/gemma-3-4b-it-Q4_K_M.gguf",
+	}, GetModelNames(found))
+	for _, model := range found {
+		assert.Equal(t, SourceLMStudio, model.Source)
+	}
+	require.NotNil(t, found[1].Projector)
+	assert.Equal(t, "lmstudio-community/gemma-3-4b-it-GGUF/mmproj-model-f16.gguf", found[1].Projector.Name)
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// quantSuffix matches quantization and precision tags in file names
+var quantSuffix = regexp.MustCompile(`(?i)[-_.]((?:i?q\d(?:_[a-z0-9]+)*)|f16|f32|bf16|fp16)$`)
+
+// IsProjector reports whether m is a multimodal projector (mmproj) file
+// rather than a model that can run on its own
+func IsProjector(m Model) bool {
+	if m.GGUF != nil && m.GGUF.Architecture == "clip" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(filepath.Base(m.Path)), "mmproj")
+}
+
+// projectorKey strips everything from a file name that differs between a
+// model and its projector: the mmproj marker, the quant tag and separators
+func projectorKey(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for {
+		trimmed := quantSuffix.ReplaceAllString(name, "")
+		if trimmed == name {
+			break
+		}
+		name = trimmed
+	}
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, "mmproj", "")
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || r == ' ' {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// minProjectorMatch is the shortest name overlap that pairs a projector
+// with a model
+const minProjectorMatch = 4
+
+// pairProjectors removes projector files from the list and attaches each
+// one to the base models in the same directory, unless a model already
+// names its projector. Each model gets the projector whose name shares the
+// longest prefix with its own. A lone projector whose name says nothing,
+// such as mmproj-F16.gguf, belongs to the models next to it only if they
+// are all quants of one model, as in a downloaded repo.
+func pairProjectors(found []Model, logger *zap.Logger) []Model {
+	projectors := make(map[string][]Model)
+	bases := make(map[string]map[string]bool) // directory -> model keys
+	var result []Model
+	for _, model := range found {
+		dir := filepath.Dir(model.Path)
+		if IsProjector(model) {
+			projectors[dir] = append(projectors[dir], model)
+			continue
+		}
+		if bases[dir] == nil {
+			bases[dir] = make(map[string]bool)
+		}
+		bases[dir][projectorKey(model.Path)] = true
+		result = append(result, model)
+	}
+
+	byPath := make(map[string]Model)
+	for _, list := range projectors {
+		for _, projector := range list {
+			byPath[projector.Path] = projector
+		}
+	}
+
+	used := make(map[string]bool)
+	for i := range result {
+		// Projectors known from a download manifest are kept
+		if known := result[i].Projector; known != nil {
+			if projector, ok := byPath[known.Path]; ok {
+				result[i].Projector = &projector
+				used[projector.Path] = true
+			}
+			continue
+		}
+
+		dir := filepath.Dir(result[i].Path)
+		candidates := projectors[dir]
+		if len(candidates) == 0 {
+			continue
+		}
+
+		best := -1
+		key := projectorKey(result[i].Path)
+		bestScore := minProjectorMatch - 1
+		for j, candidate := range candidates {
+			if score := commonPrefixLen(key, projectorKey(candidate.Path)); score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best < 0 && len(candidates) == 1 && len(bases[dir]) == 1 {
+			best = 0
+		}
+		if best < 0 {
+			continue
+		}
+
+		projector := candidates[best]
+		result[i].Projector = &projector
+		used[projector.Path] = true
+	}
+
+	for _, list := range projectors {
+		for _, projector := range list {
+			if !used[projector.Path] {
+				logger.Debug("No model found for projector", zap.String("file", projector.Path))
+			}
+		}
+	}
+	return result
+}
+
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+)
+
+func TestProjectorKey(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/m/mmproj-gemma-3-4b-it-f16.gguf", "gemma34bit"},
+		{"/m/gemma-3-4b-it-Q4_K_M.gguf", "gemma34bit"},
+		{"/m/Qwen2.5-VL-7B-Instruct-IQ4_XS.gguf", "qwen25vl7binstruct"},
+		{"/m/qwen2.5-vl-7b-instruct-mmproj-BF16.gguf", "qwen25vl7binstruct"},
+		{"/m/mmproj-F16.gguf", ""},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path), func(t *testing.T) {
+			assert.Equal(t, tt.want, projectorKey(tt.path))
+		})
+	}
+}
+
+func TestDiscoverModels_PairsProjectors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir,
+		// A lone, generically named projector belongs to the quants of the
+		// one model next to it
+		"unsloth/gemma-3-4b/gemma-3-4b-it-Q4_K_M.gguf",
+		"unsloth/gemma-3-4b/gemma-3-4b-it-Q8_0.gguf",
+		"unsloth/gemma-3-4b/mmproj-F16.gguf",
+		// Several projectors are matched by name
+		"mixed/gemma-3-12b-it-Q4_K_M.gguf",
+		"mixed/Qwen2.5-VL-7B-Instruct-Q4_K_M.gguf",
+		"mixed/llama-3-8b-Q4_K_M.gguf",
+		"mixed/mmproj-gemma-3-12b-it-f16.gguf",
+		"mixed/mmproj-Qwen2.5-VL-7B-Instruct-f16.gguf",
+		"text/phi-4-Q4_K_M.gguf",
+		// In a flat models directory a lone projector still needs a
+		// matching name
+		"flat/mmproj-F16.gguf",
+		"flat/bge-m3-Q8_0.gguf",
+		"flat/phi-4-Q4_K_M.gguf",
+		"flat2/mmproj-gemma-3-4b-it-f16.gguf",
+		"flat2/gemma-3-4b-it-Q4_K_M.gguf",
+		"flat2/bge-m3-Q8_0.gguf",
+		"flat2/phi-4-Q4_K_M.gguf",
+	)
+	// Projectors are also recognised by their GGUF architecture
+	newGGUF(3).String("general.architecture", "clip").WriteFile(t, filepath.Join(dir, "clip", "vision-encoder.gguf"))
+	writeFiles(t, dir, "clip/llava-v1.6-Q4_K_M.gguf")
+
+	cfg := &app.Config{ModelsDir: dir, ScanDepth: 3}
+	found, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	projectors := make(map[string]string)
+	for _, model := range found {
+		assert.False(t, IsProjector(model), "projector %s listed as a model", model.Name)
+		if model.Projector != nil {
+			projectors[model.Name] = model.Projector.Name
+		} else {
+			projectors[model.Name] = ""
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"unsloth/gemma-3-4b/gemma-3-4b-it-Q4_K_M.gguf": "unsloth/gemma-3-4b/mmproj-F16.gguf",
+		"unsloth/gemma-3-4b/gemma-3-4b-it-Q8_0.gguf":   "unsloth/gemma-3-4b/mmproj-F16.gguf",
+		"mixed/gemma-3-12b-it-Q4_K_M.gguf":             "mixed/mmproj-gemma-3-12b-it-f16.gguf",
+		"mixed/Qwen2.5-VL-7B-Instruct-Q4_K_M.gguf":     "mixed/mmproj-Qwen2.5-VL-7B-Instruct-f16.gguf",
+		"mixed/llama-3-8b-Q4_K_M.gguf":                 "",
+		"text/phi-4-Q4_K_M.gguf":                       "",
+		"flat/bge-m3-Q8_0.gguf":                        "",
+		"flat/phi-4-Q4_K_M.gguf":                       "",
+		"flat2/gemma-3-4b-it-Q4_K_M.gguf":              "flat2/mmproj-gemma-3-4b-it-f16.gguf",
+		"flat2/bge-m3-Q8_0.gguf":                       "",
+		"flat2/phi-4-Q4_K_M.gguf":                      "",
+		"clip/llava-v1.6-Q4_K_M.gguf":                  "clip/vision-encoder.gguf",
+	}, projectors)
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// SortMode is an order for model lists
+type SortMode int
+
+const (
+	// SortName orders by name, A to Z
+	SortName SortMode = iota
+	// SortSize puts the largest models first
+	SortSize
+	// SortModified puts the most recently modified files first
+	SortModified
+	// SortLastUsed puts the most recently launched models first, models
+	// that were never launched last
+	SortLastUsed
+	// SortParams puts the models with the most parameters first, unknown
+	// counts last
+	SortParams
+
+	sortModes
+)
+
+var sortModeNames = [...]string{"name", "size", "modified", "last used", "params"}
+
+func (s SortMode) String() string {
+	if s < 0 || s >= sortModes {
+		return "unknown"
+	}
+	return sortModeNames[s]
+}
+
+// Next returns the mode after s, wrapping around
+func (s SortMode) Next() SortMode {
+	return (s + 1) % sortModes
+}
+
+// SortModels sorts list in place. Ties, such as models that were never
+// launched, are ordered by name.
+func SortModels(list []Model, mode SortMode) {
+	slices.SortStableFunc(list, func(a, b Model) int {
+		var c int
+		switch mode {
+		case SortSize:
+			c = cmp.Compare(b.Size, a.Size)
+		case SortModified:
+			c = b.ModTime.Compare(a.ModTime)
+		case SortLastUsed:
+			c = b.LastUsed.Compare(a.LastUsed)
+		case SortParams:
+			c = cmp.Compare(b.ParameterCount(), a.ParameterCount())
+		}
+		if c != 0 {
+			return c
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+}
+
+// ParameterCount returns the number of parameters from the GGUF header,
+// 0 if unknown
+func (m Model) ParameterCount() uint64 {
+	if m.GGUF == nil {
+		return 0
+	}
+	return m.GGUF.ParameterCount
+}
+
+// FilterModels returns the models matching query, keeping their order.
+// Every space-separated word of the query has to fuzzy-match the name,
+// architecture or quantization, so "qwen q4" finds Qwen3-8B-Q4_K_M.gguf.
+func FilterModels(list []Model, query string) []Model {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return list
+	}
+
+	var matches []Model
+	for _, model := range list {
+		fields := []string{strings.ToLower(model.Name)}
+		if g := model.GGUF; g != nil {
+			fields = append(fields, strings.ToLower(g.Architecture), strings.ToLower(g.Quantization()))
+		}
+		if model.HFQuant != "" {
+			fields = append(fields, strings.ToLower(model.HFQuant))
+		}
+		if matchesAll(words, fields) {
+			matches = append(matches, model)
+		}
+	}
+	return matches
+}
+
+func matchesAll(words, fields []string) bool {
+	for _, word := range words {
+		if !slices.ContainsFunc(fields, func(field string) bool { return fuzzyMatch(word, field) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyMatch reports whether the runes of pattern appear in s in order,
+// not necessarily next to each other
+func fuzzyMatch(pattern, s string) bool {
+	for _, r := range pattern {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sortFixture() []Model {
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	return []Model{
+		{
+			Name: "qwen3-8b-q4_k_m.gguf", Size: 5 << 30, ModTime: day,
+			GGUF: &GGUFInfo{Architecture: "qwen3", FileType: 15, ParameterCount: 8_000_000_000},
+		},
+		{
+			Name: "Gemma-3-27B-Q8_0.gguf", Size: 28 << 30, ModTime: day.Add(48 * time.Hour), LastUsed: day,
+			GGUF: &GGUFInfo{Architecture: "gemma3", FileType: 7, ParameterCount: 27_000_000_000},
+		},
+		{
+			Name: "llama-3.2-1b.gguf", Size: 1 << 30, ModTime: day.Add(24 * time.Hour), LastUsed: day.Add(time.Hour),
+			GGUF: &GGUFInfo{Architecture: "llama", FileType: 1, ParameterCount: 1_200_000_000},
+		},
+		{Name: "old.bin", Size: 3 << 30, ModTime: day.Add(-24 * time.Hour)},
+	}
+}
+
+func TestSortModels(t *testing.T) {
+	tests := []struct {
+		mode SortMode
+		want []string
+	}{
+		{SortName, []string{"Gemma-3-27B-Q8_0.gguf", "llama-3.2-1b.gguf", "old.bin", "qwen3-8b-q4_k_m.gguf"}},
+		{SortSize, []string{"Gemma-3-27B-Q8_0.gguf", "qwen3-8b-q4_k_m.gguf", "old.bin", "llama-3.2-1b.gguf"}},
+		{SortModified, []string{"Gemma-3-27B-Q8_0.gguf", "llama-3.2-1b.gguf", "qwen3-8b-q4_k_m.gguf", "old.bin"}},
+		{SortLastUsed, []string{"llama-3.2-1b.gguf", "Gemma-3-27B-Q8_0.gguf", "old.bin", "qwen3-8b-q4_k_m.gguf"}},
+		{SortParams, []string{"Gemma-3-27B-Q8_0.gguf", "qwen3-8b-q4_k_m.gguf", "llama-3.2-1b.gguf", "old.bin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			list := sortFixture()
+			SortModels(list, tt.mode)
+			assert.Equal(t, tt.want, GetModelNames(list))
+		})
+	}
+}
+
+func TestSortMode_Next(t *testing.T) {
+	mode := SortName
+	var seen []string
+	for range 6 {
+		seen = append(seen, mode.String())
+		mode = mode.Next()
+	}
+	assert.Equal(t, []string{"name", "size", "modified", "last used", "params", "name"}, seen)
+}
+
+func TestFilterModels(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"qwen3-8b-q4_k_m.gguf", "Gemma-3-27B-Q8_0.gguf", "llama-3.2-1b.gguf", "old.bin"}},
+		{"qwen", []string{"qwen3-8b-q4_k_m.gguf"}},
+		{"GEMMA", []string{"Gemma-3-27B-Q8_0.gguf"}},
+		{"lm32", []string{"llama-3.2-1b.gguf"}},
+		{"gemma3", []string{"Gemma-3-27B-Q8_0.gguf"}},
+		{"llama", []string{"llama-3.2-1b.gguf"}},
+		{"q8_0", []string{"Gemma-3-27B-Q8_0.gguf"}},
+		{"f16", []string{"llama-3.2-1b.gguf"}},
+		{"qwen q4km", []string{"qwen3-8b-q4_k_m.gguf"}},
+		{"qwen q8_0", []string{}},
+		{"zzz", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetModelNames(FilterModels(sortFixture(), tt.query)))
+		})
+	}
+}
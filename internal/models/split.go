@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// splitPattern matches llama.cpp's split naming, name-00001-of-00003.gguf
+var splitPattern = regexp.MustCompile(`(?i)^(.*)-(\d{5})-of-(\d{5})\.gguf$`)
+
+// parseSplitName returns the shard prefix, shard number and shard count of
+// a split GGUF file name
+func parseSplitName(filename string) (prefix string, n, total int, ok bool) {
+	m := splitPattern.FindStringSubmatch(filename)
+	if m == nil {
+		return "", 0, 0, false
+	}
+	n, _ = strconv.Atoi(m[2])
+	total, _ = strconv.Atoi(m[3])
+	if n < 1 || total < 1 || n > total {
+		return "", 0, 0, false
+	}
+	return m[1], n, total, true
+}
+
+// splitName builds the file name of shard n
+func splitName(prefix string, n, total int) string {
+	return fmt.Sprintf("%s-%05d-of-%05d.gguf", prefix, n, total)
+}
+
+type shardGroup struct {
+	index  int // position of the merged model in the result
+	total  int
+	shards map[int]Model
+}
+
+// groupShards collapses the shards of split models into one entry that
+// sits where the first shard was found. The entry is named and launched
+// after shard 1, even when that shard is missing.
+func groupShards(found []Model, logger *zap.Logger) []Model {
+	var result []Model
+	groups := make(map[string]*shardGroup)
+	var order []string
+
+	for _, model := range found {
+		prefix, n, total, ok := parseSplitName(filepath.Base(model.Path))
+		if !ok {
+			result = append(result, model)
+			continue
+		}
+		key := fmt.Sprintf("%s\x00%s\x00%d", filepath.Dir(model.Path), prefix, total)
+		group := groups[key]
+		if group == nil {
+			group = &shardGroup{index: len(result), total: total, shards: make(map[int]Model)}
+			groups[key] = group
+			order = append(order, key)
+			result = append(result, Model{}) // placeholder
+		}
+		group.shards[n] = model
+	}
+
+	for _, key := range order {
+		group := groups[key]
+		merged := mergeShards(group)
+		if warning := merged.MissingShardsWarning(); warning != "" {
+			logger.Warn("Incomplete split model", zap.String("name", merged.Name), zap.String("problem", warning))
+		}
+		result[group.index] = merged
+	}
+	return result
+}
+
+func mergeShards(group *shardGroup) Model {
+	// Any present shard serves as the template for names and paths
+	var first Model
+	for n := 1; n <= group.total; n++ {
+		if shard, ok := group.shards[n]; ok {
+			first = shard
+			break
+		}
+	}
+	base := filepath.Base(first.Path)
+	prefix, _, total, _ := parseSplitName(base)
+	firstName := splitName(prefix, 1, total)
+
+	merged := first
+	merged.Path = filepath.Join(filepath.Dir(first.Path), firstName)
+	merged.Size = 0
+	merged.Shards = total
+	merged.GGUF = nil
+	if strings.HasSuffix(first.Name, base) {
+		merged.Name = strings.TrimSuffix(first.Name, base) + firstName
+	}
+
+	var params uint64
+	for i := 1; i <= total; i++ {
+		shard, ok := group.shards[i]
+		if !ok {
+			merged.MissingShards = append(merged.MissingShards, i)
+			continue
+		}
+		merged.Size += shard.Size
+		if shard.ModTime.After(merged.ModTime) {
+			merged.ModTime = shard.ModTime
+		}
+		if shard.GGUF != nil {
+			params += shard.GGUF.ParameterCount
+			// Shard 1 carries the model metadata. It is copied: the
+			// shard's own info is shared with the index and must keep
+			// its own parameter count.
+			if merged.GGUF == nil {
+				g := *shard.GGUF
+				merged.GGUF = &g
+			}
+		}
+	}
+	if merged.GGUF != nil {
+		merged.GGUF.ParameterCount = params
+	}
+	return merged
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+)
+
+func TestParseSplitName(t *testing.T) {
+	tests := []struct {
+		filename   string
+		wantPrefix string
+		wantN      int
+		wantTotal  int
+		wantOK     bool
+	}{
+		{"Qwen3-235B-Q4_K_M-00001-of-00003.gguf", "Qwen3-235B-Q4_K_M", 1, 3, true},
+		{"model-00003-of-00003.GGUF", "model", 3, 3, true},
+		{"model-00004-of-00003.gguf", "", 0, 0, false},
+		{"model-00000-of-00003.gguf", "", 0, 0, false},
+		{"model-1-of-3.gguf", "", 0, 0, false},
+		{"model-Q4_K_M.gguf", "", 0, 0, false},
+		{"model-00001-of-00003.bin", "", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			prefix, n, total, ok := parseSplitName(tt.filename)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantPrefix, prefix)
+			assert.Equal(t, tt.wantN, n)
+			assert.Equal(t, tt.wantTotal, total)
+		})
+	}
+}
+
+func TestDiscoverModels_GroupsShards(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir,
+		"a-single.gguf",
+		"big/model-00002-of-00003.gguf",
+		"big/model-00001-of-00003.gguf",
+		"big/model-00003-of-00003.gguf",
+		"broken/part-00002-of-00002.gguf",
+		"z-other.gguf",
+	)
+	// The first shard carries the metadata, every shard adds tensors
+	newGGUF(3).
+		String("general.architecture", "llama").
+		Uint32("split.count", 2).
+		Tensor("token_embd.weight", 1000, 1000).
+		WriteFile(t, filepath.Join(dir, "big", "model-00001-of-00003.gguf"))
+	newGGUF(3).
+		Tensor("blk.0.ffn_up.weight", 500, 1000).
+		WriteFile(t, filepath.Join(dir, "big", "model-00002-of-00003.gguf"))
+
+	cfg := &app.Config{ModelsDir: dir, ScanDepth: 2}
+	models, err := DiscoverModels(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"a-single.gguf",
+		"big/model-00001-of-00003.gguf",
+		"broken/part-00001-of-00002.gguf",
+		"z-other.gguf",
+	}, GetModelNames(models))
+
+	big := models[1]
+	assert.Equal(t, filepath.Join(dir, "big", "model-00001-of-00003.gguf"), big.Path)
+	assert.Equal(t, 3, big.Shards)
+	assert.Empty(t, big.MissingShards)
+	assert.Equal(t, "3 shards", big.ShardInfo())
+	require.NotNil(t, big.GGUF)
+	assert.Equal(t, "llama", big.GGUF.Architecture)
+	assert.Equal(t, uint64(1000*1000+500*1000), big.GGUF.ParameterCount)
+
+	broken := models[2]
+	assert.Equal(t, filepath.Join(dir, "broken", "part-00001-of-00002.gguf"), broken.Path)
+	assert.Equal(t, int64(len("model")), broken.Size)
+	assert.Equal(t, []int{1}, broken.MissingShards)
+	assert.Equal(t, "1/2 shards", broken.ShardInfo())
+	assert.Equal(t, "shard 1 of 2 missing", broken.MissingShardsWarning())
+
+	assert.Equal(t, 0, models[0].Shards)
+	assert.Empty(t, models[0].ShardInfo())
+}
+
+func TestMergeShards_KeepsShardInfo(t *testing.T) {
+	first := &GGUFInfo{Architecture: "llama", ParameterCount: 1000}
+	second := &GGUFInfo{ParameterCount: 500}
+	group := &shardGroup{total: 2, shards: map[int]Model{
+		1: {Name: "model-00001-of-00002.gguf", Path: "/m/model-00001-of-00002.gguf", GGUF: first},
+		2: {Name: "model-00002-of-00002.gguf", Path: "/m/model-00002-of-00002.gguf", GGUF: second},
+	}}
+
+	for range 2 {
+		merged := mergeShards(group)
+		require.NotNil(t, merged.GGUF)
+		assert.Equal(t, uint64(1500), merged.GGUF.ParameterCount, "merging again does not build on the last merge")
+		assert.Equal(t, "llama", merged.GGUF.Architecture)
+	}
+	assert.Equal(t, uint64(1000), first.ParameterCount, "the shard's info is unchanged")
+	assert.Equal(t, uint64(500), second.ParameterCount)
+}
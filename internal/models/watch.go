@@ -0,0 +1,208 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+)
+
+// Watcher rediscovers models whenever something changes in one of the
+// model sources. Events are debounced: a rescan only happens once no
+// further event arrived for the debounce period, so a file that is still
+// being written is not picked up half-way.
+type Watcher struct {
+	cfg      *app.Config
+	logger   *zap.Logger
+	fs       *fsnotify.Watcher
+	debounce time.Duration
+	updates  chan []Model
+	done     chan struct{}
+
+	// depths holds how many levels below each watched directory are
+	// watched as well
+	depths map[string]int
+}
+
+// NewWatcher starts watching the configured models directories and the
+// enabled caches and stores. Sources that do not exist yet are skipped.
+func NewWatcher(cfg *app.Config, logger *zap.Logger, debounce time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		cfg:      cfg,
+		logger:   logger,
+		fs:       fsw,
+		debounce: debounce,
+		updates:  make(chan []Model, 1),
+		done:     make(chan struct{}),
+		depths:   make(map[string]int),
+	}
+	for dir, depth := range watchRoots(cfg) {
+		w.add(dir, depth)
+	}
+	logger.Debug("Watching model sources", zap.Int("directories", len(w.depths)))
+
+	go w.run()
+	return w, nil
+}
+
+// Updates delivers the model list after every rescan. Only the newest
+// list is kept if the receiver falls behind.
+func (w *Watcher) Updates() <-chan []Model {
+	return w.updates
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	close(w.done)
+	return w.fs.Close()
+}
+
+// watchRoots returns the directories to watch with the number of levels
+// below them that matter, mirroring what DiscoverModels scans
+func watchRoots(cfg *app.Config) map[string]int {
+	roots := make(map[string]int)
+	for _, dir := range cfg.ModelDirs() {
+		roots[dir] = cfg.ScanDepth
+	}
+	if cfg.ScanLlamaCache {
+		roots[LlamaCacheDir()] = 0
+	}
+	if cfg.ScanHFCache {
+		// models--<org>--<name>/snapshots/<revision>/<file>
+		roots[HFCacheDir()] = 3
+	}
+	if cfg.ScanOllama {
+		for _, dir := range OllamaDirs() {
+			// manifests/<registry>/<namespace>/<model>/<tag>
+			roots[filepath.Join(dir, "manifests")] = 3
+		}
+	}
+	if cfg.ScanLMStudio {
+		for _, dir := range LMStudioDirs(cfg.LMStudioDir) {
+			roots[dir] = 2
+		}
+	}
+	return roots
+}
+
+// add watches dir and its subdirectories up to depth levels down
+func (w *Watcher) add(dir string, depth int) {
+	if _, ok := w.depths[dir]; ok {
+		return
+	}
+	if err := w.fs.Add(dir); err != nil {
+		w.logger.Debug("Not watching directory", zap.String("directory", dir), zap.Error(err))
+		return
+	}
+	w.depths[dir] = depth
+	if depth == 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if w.ignored(entry.Name()) {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			w.add(path, depth-1)
+		}
+	}
+}
+
+func (w *Watcher) ignored(name string) bool {
+	for _, pattern := range w.cfg.ScanIgnore {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// relevant reports whether an event may change the model list. Writes to
+// files that are not models, such as logs or partial downloads, are not.
+func (w *Watcher) relevant(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		return true
+	}
+	return event.Has(fsnotify.Write) && isModelFile(event.Name)
+}
+
+func (w *Watcher) run() {
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			timer.Stop()
+			return
+
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if !w.relevant(event) {
+				continue
+			}
+			w.logger.Debug("Model source changed", zap.String("path", event.Name), zap.String("op", event.Op.String()))
+
+			if event.Has(fsnotify.Create) {
+				w.watchCreated(event.Name)
+			}
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				// fsnotify drops removed watches itself
+				delete(w.depths, event.Name)
+			}
+			timer.Reset(w.debounce)
+
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Warn("File watcher error", zap.Error(err))
+
+		case <-timer.C:
+			w.rescan()
+		}
+	}
+}
+
+// watchCreated starts watching a new directory if its parent is watched
+// with levels to spare
+func (w *Watcher) watchCreated(path string) {
+	depth, ok := w.depths[filepath.Dir(path)]
+	if !ok || depth == 0 || w.ignored(filepath.Base(path)) {
+		return
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		w.add(path, depth-1)
+	}
+}
+
+func (w *Watcher) rescan() {
+	found, err := DiscoverModels(w.cfg, w.logger)
+	if err != nil {
+		w.logger.Warn("Failed to rediscover models", zap.Error(err))
+		return
+	}
+
+	// Replace a list the receiver has not picked up yet
+	select {
+	case <-w.updates:
+	default:
+	}
+	w.updates <- found
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+)
+
+func startWatcher(t *testing.T, debounce time.Duration) (*app.Config, *Watcher) {
+	t.Helper()
+	cfg := &app.Config{ModelsDir: t.TempDir(), ScanDepth: 2}
+	w, err := NewWatcher(cfg, zap.NewNop(), debounce)
+	require.NoError(t, err)
+	t.Cleanup(func() { w.Close() })
+	return cfg, w
+}
+
+func nextUpdate(t *testing.T, w *Watcher) []string {
+	t.Helper()
+	select {
+	case found := <-w.Updates():
+		names := GetModelNames(found)
+		sort.Strings(names)
+		return names
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update from watcher")
+		return nil
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	cfg, w := startWatcher(t, 20*time.Millisecond)
+
+	writeFiles(t, cfg.ModelsDir, "a.gguf")
+	require.Equal(t, []string{"a.gguf"}, nextUpdate(t, w))
+
+	// New subdirectories are watched too
+	require.NoError(t, os.Mkdir(filepath.Join(cfg.ModelsDir, "org"), 0755))
+	require.Equal(t, []string{"a.gguf"}, nextUpdate(t, w))
+	writeFiles(t, cfg.ModelsDir, "org/b.gguf")
+	require.Equal(t, []string{"a.gguf", "org/b.gguf"}, nextUpdate(t, w))
+
+	require.NoError(t, os.Remove(filepath.Join(cfg.ModelsDir, "a.gguf")))
+	require.Equal(t, []string{"org/b.gguf"}, nextUpdate(t, w))
+}
+
+func TestWatcher_Debounce(t *testing.T) {
+	cfg, w := startWatcher(t, 300*time.Millisecond)
+
+	// A file written in pieces is reported once, after the last write
+	path := filepath.Join(cfg.ModelsDir, "big.gguf")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	for range 5 {
+		_, err := f.Write(make([]byte, 1024))
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+	}
+	require.NoError(t, f.Close())
+
+	require.Equal(t, []string{"big.gguf"}, nextUpdate(t, w))
+	select {
+	case <-w.Updates():
+		t.Fatal("debounced writes caused a second rescan")
+	case <-time.After(600 * time.Millisecond):
+	}
+}
+
+func TestWatcher_IgnoresOtherFiles(t *testing.T) {
+	cfg, w := startWatcher(t, 20*time.Millisecond)
+	log := filepath.Join(cfg.ModelsDir, "server.log")
+	require.NoError(t, os.WriteFile(log, nil, 0644))
+	nextUpdate(t, w) // creating it counts
+
+	require.NoError(t, os.WriteFile(log, []byte("more output"), 0644))
+	select {
+	case <-w.Updates():
+		t.Fatal("writing a non-model file caused a rescan")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -4,360 +4,683 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 )
 
+// Mode describes how a llama.cpp instance was launched
+type Mode int
+
+const (
+	ModeServer Mode = iota
+	ModeCLI
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeServer:
+		return "server"
+	case ModeCLI:
+		return "cli"
+	default:
+		return "unknown"
+	}
+}
+
+// instance is a single running llama.cpp process with its own pipes
+type instance struct {
+	name       string
+	mode       Mode
+	model      string
+	seq        int
+	cmd        *exec.Cmd
+	stdoutPipe *os.File
+	stderrPipe *os.File
+	stdinPipe  *os.File
+	// pty is the master end of the pseudo-terminal for PTY-backed CLI
+	// instances; stdoutPipe aliases it and stdinPipe/stderrPipe are unused
+	pty     *os.File
+	started time.Time
+
+	// done is closed once cmd.Wait has returned; exit is set before that
+	done chan struct{}
+	exit *ExitStatus
+}
+
+// InstanceInfo is a read-only snapshot of a managed instance
+type InstanceInfo struct {
+	Name  string
+	Mode  Mode
+	Model string
+	PID   int
+	PTY   bool
+	// Exit is nil while the process is still running
+	Exit *ExitStatus
+}
+
+// ExitStatus describes how and when an instance's process ended
+type ExitStatus struct {
+	Instance string
+	// Code is the exit code, or -1 if the process was killed by a signal
+	Code int
+	// Signal names the signal that killed the process, if any
+	Signal   string
+	Duration time.Duration
+}
+
+func (s ExitStatus) String() string {
+	runtime := s.Duration.Round(100 * time.Millisecond)
+	if s.Signal != "" {
+		return fmt.Sprintf("killed by %s after %s", s.Signal, runtime)
+	}
+	return fmt.Sprintf("exited with code %d after %s", s.Code, runtime)
+}
+
+// LaunchSpec describes a model to launch. A non-empty HFRepo selects the
+// HuggingFace templates, otherwise ModelPath is used with the local ones.
+type LaunchSpec struct {
+	Mode      Mode
+	ModelPath string
+	ModelName string
+	HFRepo    string
+	HFQuant   string
+	HFFile    string
+	NGL       int
+	CtxSize   int
+	// Projector is the path of a multimodal projector (mmproj) file
+	Projector string
+	// Port fills {port}; 0 falls back to template_vars
+	Port int
+	// ExtraArgs is a template appended to the expanded command
+	ExtraArgs string
+	// Env holds extra KEY=VALUE entries for the process environment
+	Env []string
+}
+
+// IsHF reports whether the spec refers to a HuggingFace model
+func (s LaunchSpec) IsHF() bool {
+	return s.HFRepo != ""
+}
+
+// DisplayName is the model name used for instance names and messages
+func (s LaunchSpec) DisplayName() string {
+	if !s.IsHF() {
+		return s.ModelName
+	}
+	if s.HFQuant != "" {
+		return s.HFRepo + ":" + s.HFQuant
+	}
+	return s.HFRepo
+}
+
+// Templates holds the command templates for each kind of launch
+type Templates struct {
+	Server   string
+	CLI      string
+	ServerHF string
+	CLIHF    string
+}
+
+// DefaultTemplates returns the built-in command templates
+func DefaultTemplates() Templates {
+	return Templates{
+		Server:   "llama-server -m {model_path} [--mmproj {mmproj}] -ngl {ngl} [-c {ctx_size}] [--port {port}]",
+		CLI:      "llama-cli -m {model_path} [--mmproj {mmproj}] -ngl {ngl} [-c {ctx_size}]",
+		ServerHF: "llama-server -hf {hf_repo}[:{hf_quant}] [--hf-file {hf_file}] -ngl {ngl} [-c {ctx_size}] [--port {port}]",
+		CLIHF:    "llama-cli -hf {hf_repo}[:{hf_quant}] [--hf-file {hf_file}] -ngl {ngl} [-c {ctx_size}]",
+	}
+}
+
+// forSpec picks the template matching the spec's mode and model source
+func (t Templates) forSpec(spec LaunchSpec) string {
+	switch {
+	case spec.Mode == ModeCLI && spec.IsHF():
+		return t.CLIHF
+	case spec.Mode == ModeCLI:
+		return t.CLI
+	case spec.IsHF():
+		return t.ServerHF
+	default:
+		return t.Server
+	}
+}
+
 type ProcessManager struct {
-	cmd            *exec.Cmd
-	stdoutPipe     *os.File
-	stderrPipe     *os.File
-	stdinPipe      *os.File
-	mutex          sync.Mutex
-	logger         *zap.Logger
-	serverTemplate string
-	cliTemplate    string
+	instances    map[string]*instance
+	nextSeq      int
+	mutex        sync.Mutex
+	logger       *zap.Logger
+	templates    Templates
+	templateVars map[string]string
+	stopSignal   os.Signal
+	stopTimeout  time.Duration
+	onExit       func(ExitStatus)
+	cliPTY       bool
 }
 
 func NewProcessManager(logger *zap.Logger) *ProcessManager {
 	return &ProcessManager{
-		logger:         logger,
-		serverTemplate: "llama-server -m {model_path} -ngl {ngl}",
-		cliTemplate:    "llama-cli -m {model_path} -ngl {ngl}",
+		instances:   make(map[string]*instance),
+		logger:      logger,
+		templates:   DefaultTemplates(),
+		stopSignal:  syscall.SIGTERM,
+		stopTimeout: 10 * time.Second,
 	}
 }
 
-func (pm *ProcessManager) SetTemplates(serverTemplate, cliTemplate string) {
-	pm.serverTemplate = serverTemplate
-	pm.cliTemplate = cliTemplate
+// SetTemplates replaces the command templates. Empty fields keep their
+// current value.
+func (pm *ProcessManager) SetTemplates(templates Templates) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	if templates.Server != "" {
+		pm.templates.Server = templates.Server
+	}
+	if templates.CLI != "" {
+		pm.templates.CLI = templates.CLI
+	}
+	if templates.ServerHF != "" {
+		pm.templates.ServerHF = templates.ServerHF
+	}
+	if templates.CLIHF != "" {
+		pm.templates.CLIHF = templates.CLIHF
+	}
 }
 
-// StartServerHF starts llama-server with a HuggingFace model using -hf flag
-func (pm *ProcessManager) StartServerHF(hfModel, quant string, ngl, ctxSize int) error {
+// SetTemplateVars sets user-defined placeholders available to the command
+// templates. Built-in placeholders such as {model_path} take precedence.
+func (pm *ProcessManager) SetTemplateVars(vars map[string]string) {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
+	pm.templateVars = vars
+}
 
-	pm.stopProcessLocked()
-
-	// Format: -hf namespace/model:quant or -hf namespace/model (if no quant)
-	hfArg := hfModel
-	if quant != "" {
-		hfArg = fmt.Sprintf("%s:%s", hfModel, quant)
-	}
-
-	cmdStr := strings.NewReplacer(
-		"{model_path}", "",
-		"{model_name}", hfModel,
-		"{ngl}", fmt.Sprintf("%d", ngl),
-		"{ctx_size}", fmt.Sprintf("%d", ctxSize),
-	).Replace(pm.serverTemplate)
-
-	// Replace -m with -hf, removing the -m and its argument
-	args := strings.Fields(cmdStr)
-	var newArgs []string
-	skipNext := false
-	for i, arg := range args {
-		if skipNext {
-			// Only skip if this doesn't look like a flag
-			if !strings.HasPrefix(arg, "-") {
-				skipNext = false
-				continue
-			}
-			skipNext = false
-		}
-		if arg == "-m" {
-			newArgs = append(newArgs, "-hf", hfArg)
-			// Only skip next if it exists and isn't a flag
-			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
-				skipNext = true
-			}
-		} else if strings.HasPrefix(arg, "-m=") {
-			newArgs = append(newArgs, "-hf", hfArg)
-		} else {
-			newArgs = append(newArgs, arg)
-		}
-	}
-
-	if pm.logger != nil {
-		pm.logger.Info("Starting server with HF model",
-			zap.String("hf_model", hfModel),
-			zap.String("quant", quant),
-			zap.Strings("args", newArgs),
-			zap.Int("ngl", ngl))
-	}
+// templateVarsFor merges the user-defined variables with the built-in
+// placeholders for one launch. The caller must hold pm.mutex.
+func (pm *ProcessManager) templateVarsFor(spec LaunchSpec) map[string]string {
+	vars := make(map[string]string, len(pm.templateVars)+9)
+	for k, v := range pm.templateVars {
+		vars[k] = v
+	}
+	// {port} is always known so templates can use [--port {port}] without
+	// defining it
+	if spec.Port != 0 {
+		vars["port"] = fmt.Sprintf("%d", spec.Port)
+	} else if _, ok := vars["port"]; !ok {
+		vars["port"] = ""
+	}
+	vars["model_path"] = spec.ModelPath
+	vars["model_name"] = spec.ModelName
+	vars["hf_repo"] = spec.HFRepo
+	vars["hf_quant"] = spec.HFQuant
+	vars["hf_file"] = spec.HFFile
+	vars["mmproj"] = spec.Projector
+	vars["ngl"] = fmt.Sprintf("%d", spec.NGL)
+	vars["ctx_size"] = fmt.Sprintf("%d", spec.CtxSize)
+	return vars
+}
 
-	pm.cmd = exec.Command(newArgs[0], newArgs[1:]...)
-	pm.cmd.Env = append(os.Environ(), "PYTHONUNBUFFERED=1", "LLAMA_UNBUFFERED=1")
+// Args returns the argv Start would run for spec. A projector is passed
+// with --mmproj unless the template places {mmproj} itself.
+func (pm *ProcessManager) Args(spec LaunchSpec) ([]string, error) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	return pm.argsLocked(spec)
+}
 
-	stdoutPipe, err := pm.cmd.StdoutPipe()
+// argsLocked is Args for callers that hold pm.mutex
+func (pm *ProcessManager) argsLocked(spec LaunchSpec) ([]string, error) {
+	vars := pm.templateVarsFor(spec)
+	tmpl := pm.templates.forSpec(spec)
+	args, err := ExpandTemplate(tmpl, vars)
 	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+		return nil, fmt.Errorf("invalid command template: %w", err)
 	}
-
-	stderrPipe, err := pm.cmd.StderrPipe()
+	if len(args) == 0 {
+		return nil, fmt.Errorf("command template for %s is empty", spec.Mode)
+	}
+	if spec.Projector != "" && !strings.Contains(tmpl, "{mmproj}") {
+		args = append(args, "--mmproj", spec.Projector)
+	}
+	extra, err := ExpandTemplate(spec.ExtraArgs, vars)
 	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+		return nil, fmt.Errorf("invalid extra args: %w", err)
 	}
+	return append(args, extra...), nil
+}
 
-	if err := pm.cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start process: %w", err)
+// SetCLIPTY makes CLI instances run under a pseudo-terminal instead of plain
+// pipes, so llama-cli gets interactive mode, colors and line editing. It
+// returns an error on platforms without PTY support.
+func (pm *ProcessManager) SetCLIPTY(enabled bool) error {
+	if enabled && !ptySupported {
+		return fmt.Errorf("pty mode is only supported on Linux")
 	}
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.cliPTY = enabled
+	return nil
+}
 
-	pm.stdoutPipe = stdoutPipe.(*os.File)
-	pm.stderrPipe = stderrPipe.(*os.File)
+// SetExitHandler registers fn to be called from a background goroutine
+// whenever an instance exits on its own, i.e. without Stop being called.
+// Exited instances stay listed until they are stopped.
+func (pm *ProcessManager) SetExitHandler(fn func(ExitStatus)) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.onExit = fn
+}
 
-	if pm.logger != nil {
-		pm.logger.Info("Server started with HF model", zap.Int("pid", pm.cmd.Process.Pid))
+// SetShutdown configures how instances are stopped: sig is sent to the
+// process group first, and SIGKILL follows if the process is still alive
+// after timeout.
+func (pm *ProcessManager) SetShutdown(sig os.Signal, timeout time.Duration) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.stopSignal = sig
+	pm.stopTimeout = timeout
+}
+
+// ParseSignal converts a config value such as "SIGTERM" or "int" into a
+// signal usable with SetShutdown.
+func ParseSignal(name string) (os.Signal, error) {
+	switch strings.TrimPrefix(strings.ToUpper(name), "SIG") {
+	case "INT":
+		return syscall.SIGINT, nil
+	case "TERM":
+		return syscall.SIGTERM, nil
+	default:
+		return nil, fmt.Errorf("unsupported stop signal %q (use SIGINT or SIGTERM)", name)
+	}
+}
+
+// signalName returns the conventional SIG* name of sig
+func signalName(sig os.Signal) string {
+	switch sig {
+	case syscall.SIGINT:
+		return "SIGINT"
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	default:
+		return sig.String()
 	}
-	return nil
 }
 
-// StartCLIHF starts llama-cli with a HuggingFace model using -hf flag
-func (pm *ProcessManager) StartCLIHF(hfModel, quant string, ngl, ctxSize int) error {
+// Start launches spec as a new instance and returns the instance name.
+// Other instances keep running.
+func (pm *ProcessManager) Start(spec LaunchSpec) (string, error) {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
 
-	pm.stopProcessLocked()
-
-	// Format: -hf namespace/model:quant or -hf namespace/model (if no quant)
-	hfArg := hfModel
-	if quant != "" {
-		hfArg = fmt.Sprintf("%s:%s", hfModel, quant)
-	}
-
-	cmdStr := strings.NewReplacer(
-		"{model_path}", "",
-		"{model_name}", hfModel,
-		"{ngl}", fmt.Sprintf("%d", ngl),
-		"{ctx_size}", fmt.Sprintf("%d", ctxSize),
-	).Replace(pm.cliTemplate)
-
-	// Replace -m with -hf, removing the -m and its argument
-	args := strings.Fields(cmdStr)
-	var newArgs []string
-	skipNext := false
-	for i, arg := range args {
-		if skipNext {
-			// Only skip if this doesn't look like a flag
-			if !strings.HasPrefix(arg, "-") {
-				skipNext = false
-				continue
-			}
-			skipNext = false
-		}
-		if arg == "-m" {
-			newArgs = append(newArgs, "-hf", hfArg)
-			// Only skip next if it exists and isn't a flag
-			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
-				skipNext = true
-			}
-		} else if strings.HasPrefix(arg, "-m=") {
-			newArgs = append(newArgs, "-hf", hfArg)
-		} else {
-			newArgs = append(newArgs, arg)
+	args, err := pm.argsLocked(spec)
+	if err != nil {
+		return "", err
+	}
+
+	if pm.logger != nil {
+		pm.logger.Info("Starting process",
+			zap.String("mode", spec.Mode.String()),
+			zap.String("model", spec.DisplayName()),
+			zap.Strings("args", args),
+			zap.Int("ngl", spec.NGL),
+			zap.Int("ctx_size", spec.CtxSize))
+	}
+
+	return pm.startLocked(spec.Mode, spec.DisplayName(), args, spec.Env)
+}
+
+// startLocked launches args as a new named instance with env added to the
+// inherited environment. CLI instances also get a stdin pipe, or a pty when
+// enabled. The caller must hold pm.mutex.
+func (pm *ProcessManager) startLocked(mode Mode, model string, args, env []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("empty command")
+	}
+
+	inst := &instance{
+		name:  pm.uniqueNameLocked(model),
+		mode:  mode,
+		model: model,
+		seq:   pm.nextSeq,
+	}
+	inst.cmd = exec.Command(args[0], args[1:]...)
+	inst.cmd.Env = append(os.Environ(), "PYTHONUNBUFFERED=1", "LLAMA_UNBUFFERED=1")
+	inst.cmd.Env = append(inst.cmd.Env, env...)
+	setProcessGroup(inst.cmd)
+
+	if mode == ModeCLI && pm.cliPTY {
+		f, err := startPTY(inst.cmd)
+		if err != nil {
+			return "", fmt.Errorf("failed to start process in pty: %w", err)
 		}
+		inst.pty = f
+		inst.stdoutPipe = f
+	} else if err := inst.startWithPipes(); err != nil {
+		return "", err
 	}
 
+	inst.started = time.Now()
+	inst.done = make(chan struct{})
+	go pm.wait(inst)
+
+	pm.nextSeq++
+	pm.instances[inst.name] = inst
+
 	if pm.logger != nil {
-		pm.logger.Info("Starting CLI with HF model",
-			zap.String("hf_model", hfModel),
-			zap.String("quant", quant),
-			zap.Strings("args", newArgs),
-			zap.Int("ngl", ngl),
-			zap.Int("ctx_size", ctxSize))
+		pm.logger.Info("Process started",
+			zap.String("instance", inst.name),
+			zap.String("mode", mode.String()),
+			zap.Int("pid", inst.cmd.Process.Pid))
 	}
+	return inst.name, nil
+}
 
-	pm.cmd = exec.Command(newArgs[0], newArgs[1:]...)
-	pm.cmd.Env = append(os.Environ(), "PYTHONUNBUFFERED=1", "LLAMA_UNBUFFERED=1")
+// startWithPipes starts the instance's command with stdout and stderr (and
+// stdin for CLI instances) connected to pipes.
+func (inst *instance) startWithPipes() error {
+	// Use our own pipes rather than cmd.StdoutPipe: Wait runs in the
+	// background as soon as the process starts and would otherwise close
+	// the read ends before all output has been consumed.
+	var childFiles []*os.File
+	closeChildFiles := func() {
+		for _, f := range childFiles {
+			f.Close()
+		}
+	}
 
-	stdinPipe, err := pm.cmd.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	if inst.mode == ModeCLI {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return fmt.Errorf("failed to create stdin pipe: %w", err)
+		}
+		inst.cmd.Stdin = r
+		inst.stdinPipe = w
+		childFiles = append(childFiles, r)
 	}
 
-	stdoutPipe, err := pm.cmd.StdoutPipe()
+	stdoutR, stdoutW, err := os.Pipe()
 	if err != nil {
+		closeChildFiles()
+		inst.closePipes()
 		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
+	inst.cmd.Stdout = stdoutW
+	inst.stdoutPipe = stdoutR
+	childFiles = append(childFiles, stdoutW)
 
-	stderrPipe, err := pm.cmd.StderrPipe()
+	stderrR, stderrW, err := os.Pipe()
 	if err != nil {
+		closeChildFiles()
+		inst.closePipes()
 		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
+	inst.cmd.Stderr = stderrW
+	inst.stderrPipe = stderrR
+	childFiles = append(childFiles, stderrW)
 
-	if err := pm.cmd.Start(); err != nil {
+	err = inst.cmd.Start()
+	// The child holds its own copies of these now
+	closeChildFiles()
+	if err != nil {
+		inst.closePipes()
 		return fmt.Errorf("failed to start process: %w", err)
 	}
-
-	pm.stdinPipe = stdinPipe.(*os.File)
-	pm.stdoutPipe = stdoutPipe.(*os.File)
-	pm.stderrPipe = stderrPipe.(*os.File)
-
-	if pm.logger != nil {
-		pm.logger.Info("CLI started with HF model", zap.Int("pid", pm.cmd.Process.Pid))
-	}
 	return nil
 }
 
-func (pm *ProcessManager) StartServer(modelPath, modelName string, ngl, ctxSize int) error {
-	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
-
-	pm.stopProcessLocked()
-
-	cmdStr := strings.NewReplacer(
-		"{model_path}", modelPath,
-		"{model_name}", modelName,
-		"{ngl}", fmt.Sprintf("%d", ngl),
-		"{ctx_size}", fmt.Sprintf("%d", ctxSize),
-	).Replace(pm.serverTemplate)
+// wait reaps the instance's process, records its exit status and reports
+// unexpected exits to the exit handler.
+func (pm *ProcessManager) wait(inst *instance) {
+	inst.cmd.Wait()
 
-	if pm.logger != nil {
-		pm.logger.Info("Starting server",
-			zap.String("model", modelName),
-			zap.String("command", cmdStr),
-			zap.Int("ngl", ngl))
+	ps := inst.cmd.ProcessState
+	inst.exit = &ExitStatus{
+		Instance: inst.name,
+		Code:     ps.ExitCode(),
+		Signal:   exitSignal(ps),
+		Duration: time.Since(inst.started),
 	}
+	close(inst.done)
 
-	args := strings.Fields(cmdStr)
-	pm.cmd = exec.Command(args[0], args[1:]...)
-	pm.cmd.Env = append(os.Environ(), "PYTHONUNBUFFERED=1", "LLAMA_UNBUFFERED=1")
+	pm.mutex.Lock()
+	unexpected := pm.instances[inst.name] == inst
+	handler := pm.onExit
+	pm.mutex.Unlock()
 
-	stdoutPipe, err := pm.cmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	if unexpected {
+		if pm.logger != nil {
+			pm.logger.Info("Process exited",
+				zap.String("instance", inst.name),
+				zap.String("status", inst.exit.String()))
+		}
+		if handler != nil {
+			handler(*inst.exit)
+		}
 	}
+}
 
-	stderrPipe, err := pm.cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+// uniqueNameLocked derives an instance name from the model, appending a
+// counter when the model already has a running instance.
+func (pm *ProcessManager) uniqueNameLocked(model string) string {
+	name := model
+	for i := 2; ; i++ {
+		if _, exists := pm.instances[name]; !exists {
+			return name
+		}
+		name = fmt.Sprintf("%s#%d", model, i)
 	}
+}
 
-	if err := pm.cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start process: %w", err)
+// Stop gracefully stops the named instance and returns a description of
+// how it exited. Instances that have already exited are just removed.
+// Unknown names are ignored and yield an empty string.
+// Stop blocks for at most the configured stop timeout plus the time it
+// takes for SIGKILL to land.
+func (pm *ProcessManager) Stop(name string) string {
+	pm.mutex.Lock()
+	inst, ok := pm.instances[name]
+	if ok {
+		delete(pm.instances, name)
 	}
+	sig, timeout := pm.stopSignal, pm.stopTimeout
+	pm.mutex.Unlock()
 
-	pm.stdoutPipe = stdoutPipe.(*os.File)
-	pm.stderrPipe = stderrPipe.(*os.File)
-
-	if pm.logger != nil {
-		pm.logger.Info("CLI started", zap.Int("pid", pm.cmd.Process.Pid))
+	if !ok {
+		return ""
 	}
-	return nil
+	return pm.shutdown(inst, sig, timeout)
 }
 
-func (pm *ProcessManager) StartCLI(modelPath, modelName string, ngl, ctxSize int) error {
+// StopAll gracefully stops every managed instance in parallel
+func (pm *ProcessManager) StopAll() {
 	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
+	insts := make([]*instance, 0, len(pm.instances))
+	for name, inst := range pm.instances {
+		insts = append(insts, inst)
+		delete(pm.instances, name)
+	}
+	sig, timeout := pm.stopSignal, pm.stopTimeout
+	pm.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, inst := range insts {
+		wg.Add(1)
+		go func(inst *instance) {
+			defer wg.Done()
+			pm.shutdown(inst, sig, timeout)
+		}(inst)
+	}
+	wg.Wait()
+}
 
-	pm.stopProcessLocked()
+// shutdown sends sig to the instance's process group, escalates to SIGKILL
+// after timeout and releases its pipes. The instance must already have been
+// removed from pm.instances.
+func (pm *ProcessManager) shutdown(inst *instance, sig os.Signal, timeout time.Duration) string {
+	defer inst.closePipes()
 
-	cmdStr := strings.NewReplacer(
-		"{model_path}", modelPath,
-		"{model_name}", modelName,
-		"{ngl}", fmt.Sprintf("%d", ngl),
-		"{ctx_size}", fmt.Sprintf("%d", ctxSize),
-	).Replace(pm.cliTemplate)
+	select {
+	case <-inst.done:
+		return "already " + inst.exit.String()
+	default:
+	}
 
 	if pm.logger != nil {
-		pm.logger.Info("Starting CLI",
-			zap.String("model", modelName),
-			zap.String("command", cmdStr),
-			zap.Int("ngl", ngl),
-			zap.Int("ctx_size", ctxSize))
+		pm.logger.Info("Stopping process",
+			zap.String("instance", inst.name),
+			zap.Int("pid", inst.cmd.Process.Pid),
+			zap.String("signal", signalName(sig)),
+			zap.Duration("timeout", timeout))
 	}
 
-	args := strings.Fields(cmdStr)
-	pm.cmd = exec.Command(args[0], args[1:]...)
-	pm.cmd.Env = append(os.Environ(), "PYTHONUNBUFFERED=1", "LLAMA_UNBUFFERED=1")
+	how := fmt.Sprintf("stopped with %s", signalName(sig))
+	if err := signalGroup(inst.cmd.Process, sig); err != nil && pm.logger != nil {
+		pm.logger.Debug("Failed to signal process group", zap.String("instance", inst.name), zap.Error(err))
+	}
 
-	stdinPipe, err := pm.cmd.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	select {
+	case <-inst.done:
+	case <-time.After(timeout):
+		how = fmt.Sprintf("killed with SIGKILL after %s grace period", timeout)
+		if err := killGroup(inst.cmd.Process); err != nil && pm.logger != nil {
+			pm.logger.Warn("Failed to kill process group", zap.String("instance", inst.name), zap.Error(err))
+		}
+		<-inst.done
 	}
 
-	stdoutPipe, err := pm.cmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	reason := fmt.Sprintf("%s (%s)", how, inst.cmd.ProcessState)
+	if pm.logger != nil {
+		pm.logger.Info("Process stopped", zap.String("instance", inst.name), zap.String("reason", reason))
 	}
+	return reason
+}
 
-	stderrPipe, err := pm.cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+// closePipes closes our ends of the instance's pipes
+func (inst *instance) closePipes() {
+	if inst.pty != nil {
+		inst.pty.Close()
+		inst.pty = nil
+		inst.stdoutPipe = nil
 	}
 
-	if err := pm.cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start process: %w", err)
+	if inst.stdinPipe != nil {
+		inst.stdinPipe.Close()
+		inst.stdinPipe = nil
 	}
 
-	pm.stdinPipe = stdinPipe.(*os.File)
-	pm.stdoutPipe = stdoutPipe.(*os.File)
-	pm.stderrPipe = stderrPipe.(*os.File)
+	if inst.stdoutPipe != nil {
+		inst.stdoutPipe.Close()
+		inst.stdoutPipe = nil
+	}
 
-	if pm.logger != nil {
-		pm.logger.Info("CLI started", zap.Int("pid", pm.cmd.Process.Pid))
+	if inst.stderrPipe != nil {
+		inst.stderrPipe.Close()
+		inst.stderrPipe = nil
 	}
-	return nil
 }
 
-func (pm *ProcessManager) Stop() {
+// IsRunning reports whether the named instance's process is still alive
+func (pm *ProcessManager) IsRunning(name string) bool {
 	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
-	pm.stopProcessLocked()
-}
-
-func (pm *ProcessManager) stopProcessLocked() {
-	if pm.cmd != nil && pm.cmd.Process != nil {
-		if pm.logger != nil {
-			pm.logger.Info("Stopping process", zap.Int("pid", pm.cmd.Process.Pid))
-		}
-		pm.cmd.Process.Kill()
-		pm.cmd.Wait()
-		pm.cmd = nil
+	inst, ok := pm.instances[name]
+	pm.mutex.Unlock()
+	if !ok {
+		return false
 	}
 
-	if pm.stdinPipe != nil {
-		pm.stdinPipe.Close()
-		pm.stdinPipe = nil
+	select {
+	case <-inst.done:
+		return false
+	default:
+		return true
 	}
+}
 
-	if pm.stdoutPipe != nil {
-		pm.stdoutPipe.Close()
-		pm.stdoutPipe = nil
+// List returns all managed instances in start order, including ones that
+// have exited but not been stopped yet
+func (pm *ProcessManager) List() []InstanceInfo {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	insts := make([]*instance, 0, len(pm.instances))
+	for _, inst := range pm.instances {
+		insts = append(insts, inst)
 	}
+	sort.Slice(insts, func(i, j int) bool { return insts[i].seq < insts[j].seq })
 
-	if pm.stderrPipe != nil {
-		pm.stderrPipe.Close()
-		pm.stderrPipe = nil
+	infos := make([]InstanceInfo, len(insts))
+	for i, inst := range insts {
+		infos[i] = InstanceInfo{
+			Name:  inst.name,
+			Mode:  inst.mode,
+			Model: inst.model,
+			PID:   inst.cmd.Process.Pid,
+			PTY:   inst.pty != nil,
+		}
+		select {
+		case <-inst.done:
+			infos[i].Exit = inst.exit
+		default:
+		}
 	}
+	return infos
 }
 
-func (pm *ProcessManager) IsRunning() bool {
+func (pm *ProcessManager) GetOutputPipes(name string) (*os.File, *os.File) {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
-	return pm.cmd != nil && pm.cmd.Process != nil
+	inst, ok := pm.instances[name]
+	if !ok {
+		return nil, nil
+	}
+	return inst.stdoutPipe, inst.stderrPipe
 }
 
-func (pm *ProcessManager) GetOutputPipes() (*os.File, *os.File) {
+// GetStdinPipe returns the file input for the named instance is written to:
+// its stdin pipe, or the pty for PTY-backed instances.
+func (pm *ProcessManager) GetStdinPipe(name string) *os.File {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
-	return pm.stdoutPipe, pm.stderrPipe
+	inst, ok := pm.instances[name]
+	if !ok {
+		return nil
+	}
+	if inst.pty != nil {
+		return inst.pty
+	}
+	return inst.stdinPipe
 }
 
-func (pm *ProcessManager) GetStdinPipe() *os.File {
+func (pm *ProcessManager) WriteToStdin(name string, data []byte) error {
+	stdin := pm.GetStdinPipe(name)
+	if stdin == nil {
+		return fmt.Errorf("stdin pipe not available")
+	}
+	_, err := stdin.Write(data)
+	return err
+}
+
+// IsPTY reports whether the named instance runs under a pseudo-terminal
+func (pm *ProcessManager) IsPTY(name string) bool {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
-	return pm.stdinPipe
+	inst, ok := pm.instances[name]
+	return ok && inst.pty != nil
 }
 
-func (pm *ProcessManager) WriteToStdin(data []byte) error {
+// Resize sets the terminal size of a PTY-backed instance. It is a no-op for
+// instances using plain pipes.
+func (pm *ProcessManager) Resize(name string, cols, rows int) error {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
-	if pm.stdinPipe == nil {
-		return fmt.Errorf("stdin pipe not available")
+	inst, ok := pm.instances[name]
+	if !ok || inst.pty == nil {
+		return nil
 	}
-	_, err := pm.stdinPipe.Write(data)
-	return err
+	return resizePTY(inst.pty, cols, rows)
 }
@@ -0,0 +1,438 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestHelperProcess is not a real test. It is re-executed by the tests below
+// as a stand-in for llama-server; LLOADER_HELPER_PROCESS selects how it
+// reacts to shutdown signals.
+func TestHelperProcess(t *testing.T) {
+	behavior := os.Getenv("LLOADER_HELPER_PROCESS")
+	if behavior == "" {
+		return
+	}
+
+	sigs := make(chan os.Signal, 1)
+	switch behavior {
+	case "graceful":
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	case "stubborn":
+		signal.Ignore(syscall.SIGINT, syscall.SIGTERM)
+	}
+	fmt.Println("ready")
+	if behavior == "crash" {
+		fmt.Fprintln(os.Stderr, "error: failed to load model")
+		os.Exit(1)
+	}
+
+	select {
+	case sig := <-sigs:
+		fmt.Printf("received %v, saving slots\n", sig)
+		os.Exit(0)
+	case <-time.After(30 * time.Second):
+		os.Exit(2)
+	}
+}
+
+// startHelper launches the test binary as a server instance
+func startHelper(t *testing.T, pm *ProcessManager, behavior string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not delivered to child processes on Windows")
+	}
+	t.Setenv("LLOADER_HELPER_PROCESS", behavior)
+	pm.SetTemplates(Templates{Server: "{model_path} -test.run=^TestHelperProcess$"})
+
+	name, err := pm.Start(LaunchSpec{Mode: ModeServer, ModelPath: os.Args[0], ModelName: behavior})
+	require.NoError(t, err)
+
+	// Wait until the helper has installed its signal handlers
+	buf := make([]byte, 64)
+	stdout, _ := pm.GetOutputPipes(name)
+	n, err := stdout.Read(buf)
+	require.NoError(t, err)
+	require.Contains(t, string(buf[:n]), "ready")
+	return name
+}
+
+func TestProcessManager_MultipleInstances(t *testing.T) {
+	pm := NewProcessManager(zap.NewNop())
+	pm.SetTemplates(Templates{Server: "sleep 30", CLI: "sleep 30"})
+	defer pm.StopAll()
+
+	embed, err := pm.Start(LaunchSpec{Mode: ModeServer, ModelPath: "/models/embed.gguf", ModelName: "embed.gguf"})
+	require.NoError(t, err)
+	chat, err := pm.Start(LaunchSpec{Mode: ModeCLI, ModelPath: "/models/chat.gguf", ModelName: "chat.gguf"})
+	require.NoError(t, err)
+	chat2, err := pm.Start(LaunchSpec{Mode: ModeServer, ModelPath: "/models/chat.gguf", ModelName: "chat.gguf"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "embed.gguf", embed)
+	assert.Equal(t, "chat.gguf", chat)
+	assert.Equal(t, "chat.gguf#2", chat2)
+
+	list := pm.List()
+	require.Len(t, list, 3)
+	assert.Equal(t, []string{embed, chat, chat2}, []string{list[0].Name, list[1].Name, list[2].Name})
+	assert.Equal(t, ModeServer, list[0].Mode)
+	assert.Equal(t, ModeCLI, list[1].Mode)
+	assert.NotZero(t, list[0].PID)
+
+	assert.NotNil(t, pm.GetStdinPipe(chat))
+	assert.Nil(t, pm.GetStdinPipe(embed))
+
+	pm.Stop(chat)
+	assert.False(t, pm.IsRunning(chat))
+	assert.True(t, pm.IsRunning(embed))
+	assert.True(t, pm.IsRunning(chat2))
+	assert.Len(t, pm.List(), 2)
+
+	pm.StopAll()
+	assert.Empty(t, pm.List())
+}
+
+func TestProcessManager_UnknownInstance(t *testing.T) {
+	pm := NewProcessManager(zap.NewNop())
+
+	assert.False(t, pm.IsRunning("missing"))
+	assert.Error(t, pm.WriteToStdin("missing", []byte("hi\n")))
+	stdout, stderr := pm.GetOutputPipes("missing")
+	assert.Nil(t, stdout)
+	assert.Nil(t, stderr)
+	assert.Empty(t, pm.Stop("missing"))
+}
+
+func TestProcessManager_StopGraceful(t *testing.T) {
+	tests := []struct {
+		name   string
+		signal os.Signal
+		want   string
+	}{
+		{"SIGTERM", syscall.SIGTERM, "stopped with SIGTERM (exit status 0)"},
+		{"SIGINT", syscall.SIGINT, "stopped with SIGINT (exit status 0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := NewProcessManager(zap.NewNop())
+			pm.SetShutdown(tt.signal, 5*time.Second)
+			name := startHelper(t, pm, "graceful")
+
+			start := time.Now()
+			reason := pm.Stop(name)
+
+			assert.Equal(t, tt.want, reason)
+			assert.Less(t, time.Since(start), 5*time.Second)
+			assert.False(t, pm.IsRunning(name))
+		})
+	}
+}
+
+func TestProcessManager_StopEscalatesToKill(t *testing.T) {
+	pm := NewProcessManager(zap.NewNop())
+	pm.SetShutdown(syscall.SIGTERM, 200*time.Millisecond)
+	name := startHelper(t, pm, "stubborn")
+
+	start := time.Now()
+	reason := pm.Stop(name)
+
+	assert.Equal(t, "killed with SIGKILL after 200ms grace period (signal: killed)", reason)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.False(t, pm.IsRunning(name))
+}
+
+func TestParseSignal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    os.Signal
+		wantErr bool
+	}{
+		{"SIGTERM", syscall.SIGTERM, false},
+		{"term", syscall.SIGTERM, false},
+		{"SIGINT", syscall.SIGINT, false},
+		{"Int", syscall.SIGINT, false},
+		{"SIGKILL", nil, true},
+		{"", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			sig, err := ParseSignal(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, sig)
+		})
+	}
+}
+
+func TestProcessManager_DetectsExit(t *testing.T) {
+	exits := make(chan ExitStatus, 1)
+	pm := NewProcessManager(zap.NewNop())
+	pm.SetExitHandler(func(status ExitStatus) { exits <- status })
+	name := startHelper(t, pm, "crash")
+
+	select {
+	case status := <-exits:
+		assert.Equal(t, name, status.Instance)
+		assert.Equal(t, 1, status.Code)
+		assert.Empty(t, status.Signal)
+		assert.Contains(t, status.String(), "exited with code 1 after ")
+	case <-time.After(5 * time.Second):
+		t.Fatal("exit was not reported")
+	}
+
+	assert.False(t, pm.IsRunning(name))
+	list := pm.List()
+	require.Len(t, list, 1)
+	require.NotNil(t, list[0].Exit)
+	assert.Equal(t, 1, list[0].Exit.Code)
+
+	// Stopping an exited instance just removes it
+	assert.Contains(t, pm.Stop(name), "already exited with code 1")
+	assert.Empty(t, pm.List())
+}
+
+func TestProcessManager_StopDoesNotReportExit(t *testing.T) {
+	exits := make(chan ExitStatus, 1)
+	pm := NewProcessManager(zap.NewNop())
+	pm.SetExitHandler(func(status ExitStatus) { exits <- status })
+	name := startHelper(t, pm, "graceful")
+
+	pm.Stop(name)
+
+	select {
+	case status := <-exits:
+		t.Fatalf("unexpected exit report: %v", status)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestExitStatus_String(t *testing.T) {
+	tests := []struct {
+		status ExitStatus
+		want   string
+	}{
+		{ExitStatus{Code: 1, Duration: 2340 * time.Millisecond}, "exited with code 1 after 2.3s"},
+		{ExitStatus{Code: 0, Duration: 90 * time.Second}, "exited with code 0 after 1m30s"},
+		{ExitStatus{Code: -1, Signal: "SIGSEGV", Duration: 500 * time.Millisecond}, "killed by SIGSEGV after 500ms"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.status.String())
+	}
+}
+
+func TestProcessManager_CLIPTY(t *testing.T) {
+	if !ptySupported {
+		t.Skip("pty mode not supported on this platform")
+	}
+
+	pm := NewProcessManager(zap.NewNop())
+	require.NoError(t, pm.SetCLIPTY(true))
+	pm.SetTemplates(Templates{CLI: "cat"})
+	defer pm.StopAll()
+
+	name, err := pm.Start(LaunchSpec{Mode: ModeCLI, ModelPath: "/models/chat.gguf", ModelName: "chat.gguf"})
+	require.NoError(t, err)
+	assert.True(t, pm.IsPTY(name))
+	assert.True(t, pm.List()[0].PTY)
+	assert.NoError(t, pm.Resize(name, 120, 40))
+
+	stdout, stderr := pm.GetOutputPipes(name)
+	require.NotNil(t, stdout)
+	assert.Nil(t, stderr)
+
+	require.NoError(t, pm.WriteToStdin(name, []byte("hello\n")))
+
+	var got []byte
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(string(got), "hello\r\nhello") && time.Now().Before(deadline) {
+		n, err := stdout.Read(buf)
+		require.NoError(t, err)
+		got = append(got, buf[:n]...)
+	}
+	// Echoed by the terminal, then printed by cat
+	assert.Contains(t, string(got), "hello\r\nhello")
+}
+
+func TestProcessManager_Args(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates Templates
+		spec      LaunchSpec
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name: "local server",
+			spec: LaunchSpec{Mode: ModeServer, ModelPath: "/models/a b.gguf", ModelName: "a b.gguf", NGL: 99, CtxSize: 4096},
+			want: []string{"llama-server", "-m", "/models/a b.gguf", "-ngl", "99", "-c", "4096"},
+		},
+		{
+			name: "local cli without context size",
+			spec: LaunchSpec{Mode: ModeCLI, ModelPath: "/models/a.gguf", ModelName: "a.gguf", NGL: 0},
+			want: []string{"llama-cli", "-m", "/models/a.gguf", "-ngl", "0"},
+		},
+		{
+			name: "hf server with quant",
+			spec: LaunchSpec{Mode: ModeServer, HFRepo: "org/model-GGUF", HFQuant: "Q4_K_M", NGL: 99},
+			want: []string{"llama-server", "-hf", "org/model-GGUF:Q4_K_M", "-ngl", "99"},
+		},
+		{
+			name: "hf server without quant",
+			spec: LaunchSpec{Mode: ModeServer, HFRepo: "org/model-GGUF", NGL: 99, CtxSize: 8192},
+			want: []string{"llama-server", "-hf", "org/model-GGUF", "-ngl", "99", "-c", "8192"},
+		},
+		{
+			name: "hf cli with file",
+			spec: LaunchSpec{Mode: ModeCLI, HFRepo: "org/model-GGUF", HFFile: "model-Q8_0.gguf", NGL: 10},
+			want: []string{"llama-cli", "-hf", "org/model-GGUF", "--hf-file", "model-Q8_0.gguf", "-ngl", "10"},
+		},
+		{
+			name:      "custom hf template with long flags",
+			templates: Templates{ServerHF: "llama-server --port 8080 --hf-repo {hf_repo}[:{hf_quant}] --model-alias {model_name}"},
+			spec:      LaunchSpec{Mode: ModeServer, HFRepo: "org/model-GGUF", HFQuant: "IQ4_NL", ModelName: "org/model-GGUF"},
+			want:      []string{"llama-server", "--port", "8080", "--hf-repo", "org/model-GGUF:IQ4_NL", "--model-alias", "org/model-GGUF"},
+		},
+		{
+			name:      "local template is not used for hf",
+			templates: Templates{Server: "local-only {model_path}"},
+			spec:      LaunchSpec{Mode: ModeServer, HFRepo: "org/model-GGUF"},
+			want:      []string{"llama-server", "-hf", "org/model-GGUF", "-ngl", "0"},
+		},
+		{
+			name: "port and extra args",
+			spec: LaunchSpec{Mode: ModeServer, ModelPath: "/models/a.gguf", NGL: 99, Port: 8081, ExtraArgs: "--jinja -fa --alias {model_name}", ModelName: "a.gguf"},
+			want: []string{"llama-server", "-m", "/models/a.gguf", "-ngl", "99", "--port", "8081", "--jinja", "-fa", "--alias", "a.gguf"},
+		},
+		{
+			name:      "extra args with quoting",
+			templates: Templates{CLI: "llama-cli -m {model_path}"},
+			spec:      LaunchSpec{Mode: ModeCLI, ModelPath: "/models/a.gguf", ExtraArgs: `--chat-template-file "/tmp/my template.jinja"`},
+			want:      []string{"llama-cli", "-m", "/models/a.gguf", "--chat-template-file", "/tmp/my template.jinja"},
+		},
+		{
+			name: "projector in default template",
+			spec: LaunchSpec{Mode: ModeCLI, ModelPath: "/models/gemma.gguf", Projector: "/models/mmproj-gemma.gguf", NGL: 99},
+			want: []string{"llama-cli", "-m", "/models/gemma.gguf", "--mmproj", "/models/mmproj-gemma.gguf", "-ngl", "99"},
+		},
+		{
+			name:      "projector appended to templates without {mmproj}",
+			templates: Templates{Server: "llama-server --model {model_path}"},
+			spec:      LaunchSpec{Mode: ModeServer, ModelPath: "/models/gemma.gguf", Projector: "/models/mmproj.gguf", ExtraArgs: "--jinja"},
+			want:      []string{"llama-server", "--model", "/models/gemma.gguf", "--mmproj", "/models/mmproj.gguf", "--jinja"},
+		},
+		{
+			name:    "invalid extra args",
+			spec:    LaunchSpec{Mode: ModeServer, ModelPath: "/models/a.gguf", ExtraArgs: "--temp 0.7 [--seed"},
+			wantErr: true,
+		},
+		{
+			name:      "template that expands to nothing",
+			templates: Templates{CLI: "{model_path}"},
+			spec:      LaunchSpec{Mode: ModeCLI},
+			wantErr:   true,
+		},
+		{
+			name:      "unknown placeholder",
+			templates: Templates{Server: "llama-server --host {host}"},
+			spec:      LaunchSpec{Mode: ModeServer, ModelPath: "/models/a.gguf"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := NewProcessManager(nil)
+			pm.SetTemplates(tt.templates)
+
+			args, err := pm.Args(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, args)
+		})
+	}
+}
+
+func TestProcessManager_PortFallsBackToTemplateVars(t *testing.T) {
+	pm := NewProcessManager(nil)
+	pm.SetTemplateVars(map[string]string{"port": "9000"})
+
+	args, err := pm.Args(LaunchSpec{Mode: ModeServer, ModelPath: "/models/a.gguf"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"llama-server", "-m", "/models/a.gguf", "-ngl", "0", "--port", "9000"}, args)
+
+	args, err = pm.Args(LaunchSpec{Mode: ModeServer, ModelPath: "/models/a.gguf", Port: 9001})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"llama-server", "-m", "/models/a.gguf", "-ngl", "0", "--port", "9001"}, args)
+}
+
+// TestProcessManager_SetTemplatesConcurrently is meant for -race: config
+// reloads replace the templates while instances start
+func TestProcessManager_SetTemplatesConcurrently(t *testing.T) {
+	pm := NewProcessManager(nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 100 {
+			pm.SetTemplates(Templates{Server: fmt.Sprintf("llama-server-%d -m {model_path}", i)})
+			pm.SetTemplateVars(map[string]string{"port": fmt.Sprint(9000 + i)})
+		}
+	}()
+	for range 100 {
+		args, err := pm.Args(LaunchSpec{Mode: ModeServer, ModelPath: "/models/a.gguf"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"-m", "/models/a.gguf"}, args[1:3])
+	}
+	<-done
+}
+
+func TestProcessManager_Env(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper process relies on unix signals")
+	}
+	pm := NewProcessManager(zap.NewNop())
+	defer pm.StopAll()
+
+	// The spec's environment wins over the inherited one
+	t.Setenv("LLOADER_HELPER_PROCESS", "graceful")
+	pm.SetTemplates(Templates{Server: "{model_path} -test.run=^TestHelperProcess$"})
+	name, err := pm.Start(LaunchSpec{
+		Mode:      ModeServer,
+		ModelPath: os.Args[0],
+		ModelName: "env",
+		Env:       []string{"LLOADER_HELPER_PROCESS=crash"},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return !pm.IsRunning(name) }, 5*time.Second, 10*time.Millisecond)
+	infos := pm.List()
+	require.Len(t, infos, 1)
+	require.NotNil(t, infos[0].Exit)
+	assert.Equal(t, 1, infos[0].Exit.Code)
+}
+
+func TestLaunchSpec_DisplayName(t *testing.T) {
+	assert.Equal(t, "a.gguf", LaunchSpec{ModelPath: "/models/a.gguf", ModelName: "a.gguf"}.DisplayName())
+	assert.Equal(t, "org/model:Q4_K_M", LaunchSpec{HFRepo: "org/model", HFQuant: "Q4_K_M"}.DisplayName())
+	assert.Equal(t, "org/model", LaunchSpec{HFRepo: "org/model"}.DisplayName())
+}
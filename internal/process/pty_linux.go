@@ -0,0 +1,28 @@
+//go:build linux
+
+package process
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+// ptySupported reports whether CLI instances can run under a pseudo-terminal
+const ptySupported = true
+
+// startPTY starts cmd attached to a new pseudo-terminal and returns the
+// master end, which carries both input and output.
+func startPTY(cmd *exec.Cmd) (*os.File, error) {
+	// pty starts a new session, which already makes the child its own
+	// process group leader; asking for setpgid on top of that fails
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+	return pty.StartWithSize(cmd, &pty.Winsize{Cols: 80, Rows: 24})
+}
+
+// resizePTY updates the terminal size seen by the child
+func resizePTY(f *os.File, cols, rows int) error {
+	return pty.Setsize(f, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package process
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+const ptySupported = false
+
+var errPTYUnsupported = errors.New("pty mode is only supported on Linux")
+
+func startPTY(cmd *exec.Cmd) (*os.File, error) {
+	return nil, errPTYUnsupported
+}
+
+func resizePTY(f *os.File, cols, rows int) error {
+	return errPTYUnsupported
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package process
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalGroup kills p; Windows has no way to deliver SIGINT/SIGTERM to a
+// console child that is not attached to our console.
+func signalGroup(p *os.Process, sig os.Signal) error {
+	return p.Kill()
+}
+
+// killGroup kills p
+func killGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+// exitSignal always returns "" since Windows has no termination signals
+func exitSignal(ps *os.ProcessState) string {
+	return ""
+}
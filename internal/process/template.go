@@ -0,0 +1,351 @@
+package process
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Command templates are split into arguments with shell-style rules:
+//
+//   - whitespace separates arguments, unless it is quoted or escaped
+//   - 'single quotes' keep their content verbatim, placeholders included
+//   - "double quotes" group words but still expand placeholders
+//   - a backslash outside quotes escapes the next character; inside
+//     double quotes it only escapes $, `, ", \ and newline, as in a POSIX
+//     shell, and is kept before anything else
+//   - {name} is replaced by the value of variable name; the value never
+//     gets split, so paths with spaces stay one argument
+//   - [ ... ] marks an optional section that is dropped entirely when any
+//     placeholder directly inside it is empty or "0", e.g. [-c {ctx_size}].
+//     Sections may also sit inside a single argument, as in
+//     {hf_repo}[:{hf_quant}], but then must not contain whitespace.
+//
+// An unquoted argument that expands to nothing is dropped; a quoted one is
+// kept as an empty argument.
+
+// templatePart is a literal run of text, a placeholder or an optional
+// section inside a word
+type templatePart struct {
+	text     string
+	variable bool
+	group    []templatePart
+}
+
+// templateNode is either a single word or an optional group of nodes
+type templateNode struct {
+	parts  []templatePart
+	quoted bool
+	group  []templateNode
+}
+
+// ExpandTemplate renders a command template into an argument vector
+func ExpandTemplate(tmpl string, vars map[string]string) ([]string, error) {
+	p := &templateParser{input: []rune(tmpl)}
+	nodes, err := p.parse(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	if err := expandNodes(nodes, vars, &args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+func expandNodes(nodes []templateNode, vars map[string]string, args *[]string) error {
+	for _, node := range nodes {
+		if node.group != nil {
+			if err := expandGroup(node.group, vars, args); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var word strings.Builder
+		if err := expandParts(node.parts, vars, &word); err != nil {
+			return err
+		}
+		if word.Len() > 0 || node.quoted {
+			*args = append(*args, word.String())
+		}
+	}
+	return nil
+}
+
+func expandParts(parts []templatePart, vars map[string]string, word *strings.Builder) error {
+	for _, part := range parts {
+		switch {
+		case part.group != nil:
+			keep, err := sectionEnabled(part.group, vars)
+			if err != nil {
+				return err
+			}
+			if keep {
+				if err := expandParts(part.group, vars, word); err != nil {
+					return err
+				}
+			}
+		case part.variable:
+			value, ok := vars[part.text]
+			if !ok {
+				return fmt.Errorf("unknown placeholder {%s}", part.text)
+			}
+			word.WriteString(value)
+		default:
+			word.WriteString(part.text)
+		}
+	}
+	return nil
+}
+
+// expandGroup expands an optional section unless one of its own
+// placeholders is empty or "0". Nested sections decide for themselves.
+func expandGroup(nodes []templateNode, vars map[string]string, args *[]string) error {
+	for _, node := range nodes {
+		keep, err := sectionEnabled(node.parts, vars)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			return nil
+		}
+	}
+	return expandNodes(nodes, vars, args)
+}
+
+// sectionEnabled reports whether all placeholders directly in parts are set
+func sectionEnabled(parts []templatePart, vars map[string]string) (bool, error) {
+	for _, part := range parts {
+		if !part.variable {
+			continue
+		}
+		value, ok := vars[part.text]
+		if !ok {
+			return false, fmt.Errorf("unknown placeholder {%s}", part.text)
+		}
+		if value == "" || value == "0" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+type templateParser struct {
+	input []rune
+	pos   int
+}
+
+// parse reads words until the end of input, or until the closing bracket
+// when inGroup is set
+func (p *templateParser) parse(inGroup bool) ([]templateNode, error) {
+	var nodes []templateNode
+	for {
+		for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+			p.pos++
+		}
+		if p.pos >= len(p.input) {
+			if inGroup {
+				return nil, fmt.Errorf("unterminated optional section: missing ']'")
+			}
+			return nodes, nil
+		}
+
+		switch p.input[p.pos] {
+		case '[':
+			p.pos++
+			group, err := p.parse(true)
+			if err != nil {
+				return nil, err
+			}
+			// Keep empty groups distinguishable from plain words
+			if group == nil {
+				group = []templateNode{}
+			}
+			nodes = append(nodes, templateNode{group: group})
+		case ']':
+			if inGroup {
+				p.pos++
+				return nodes, nil
+			}
+			return nil, fmt.Errorf("unexpected ']' at position %d", p.pos)
+		default:
+			word, closed, err := p.parseWord(inGroup)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, word)
+			if closed {
+				return nodes, nil
+			}
+		}
+	}
+}
+
+// doubleQuoteEscapes are the characters a backslash escapes inside double
+// quotes
+const doubleQuoteEscapes = "$`\"\\\n"
+
+// parseWord reads a single argument. closed reports whether the word was
+// terminated by the closing bracket of the enclosing group.
+func (p *templateParser) parseWord(inGroup bool) (node templateNode, closed bool, err error) {
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			node.parts = append(node.parts, templatePart{text: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	quote := rune(0)
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				literal.WriteRune(r)
+			}
+		case r == '\\' && p.pos+1 < len(p.input) && (quote == 0 || strings.ContainsRune(doubleQuoteEscapes, p.input[p.pos+1])):
+			p.pos++
+			literal.WriteRune(p.input[p.pos])
+		case r == '{':
+			if name, ok := p.placeholder(); ok {
+				flush()
+				node.parts = append(node.parts, templatePart{text: name, variable: true})
+				continue
+			}
+			literal.WriteRune(r)
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				literal.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			node.quoted = true
+		case unicode.IsSpace(r):
+			flush()
+			return node, false, nil
+		case r == ']' && inGroup:
+			p.pos++
+			flush()
+			return node, true, nil
+		case r == '[':
+			p.pos++
+			group, err := p.parseInline()
+			if err != nil {
+				return node, false, err
+			}
+			flush()
+			node.parts = append(node.parts, templatePart{group: group})
+			continue
+		default:
+			literal.WriteRune(r)
+		}
+		p.pos++
+	}
+
+	if quote != 0 {
+		return node, false, fmt.Errorf("unterminated %c quote", quote)
+	}
+	flush()
+	return node, false, nil
+}
+
+// parseInline reads an optional section inside a word, up to and including
+// its closing bracket
+func (p *templateParser) parseInline() ([]templatePart, error) {
+	parts := []templatePart{}
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			parts = append(parts, templatePart{text: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		switch {
+		case r == '\\' && p.pos+1 < len(p.input):
+			p.pos++
+			literal.WriteRune(p.input[p.pos])
+		case r == '{':
+			if name, ok := p.placeholder(); ok {
+				flush()
+				parts = append(parts, templatePart{text: name, variable: true})
+				continue
+			}
+			literal.WriteRune(r)
+		case r == '[':
+			p.pos++
+			group, err := p.parseInline()
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			parts = append(parts, templatePart{group: group})
+			continue
+		case r == ']':
+			p.pos++
+			flush()
+			return parts, nil
+		case unicode.IsSpace(r) || r == '\'' || r == '"':
+			return nil, fmt.Errorf("optional section inside an argument cannot contain whitespace or quotes (position %d)", p.pos)
+		default:
+			literal.WriteRune(r)
+		}
+		p.pos++
+	}
+	return nil, fmt.Errorf("unterminated optional section: missing ']'")
+}
+
+// placeholder consumes {name} at the current position if it is one
+func (p *templateParser) placeholder() (string, bool) {
+	end := p.pos + 1
+	for end < len(p.input) && isPlaceholderRune(p.input[end]) {
+		end++
+	}
+	if end == p.pos+1 || end >= len(p.input) || p.input[end] != '}' {
+		return "", false
+	}
+	name := string(p.input[p.pos+1 : end])
+	p.pos = end + 1
+	return name, true
+}
+
+func isPlaceholderRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// QuoteArgs joins args into a single line that a POSIX shell would split
+// back into the same arguments
+func QuoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteArg(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func quoteArg(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range arg {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./:=,+@%", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
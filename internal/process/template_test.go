@@ -0,0 +1,147 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	vars := map[string]string{
+		"model_path": "/models/My Model/q4.gguf",
+		"model_name": "q4.gguf",
+		"ngl":        "99",
+		"ctx_size":   "0",
+		"host":       "0.0.0.0",
+		"empty":      "",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "path with spaces stays one argument",
+			template: "llama-server -m {model_path} -ngl {ngl}",
+			want:     []string{"llama-server", "-m", "/models/My Model/q4.gguf", "-ngl", "99"},
+		},
+		{
+			name:     "double quotes expand placeholders",
+			template: `llama-cli -p "You are {model_name}, be brief"`,
+			want:     []string{"llama-cli", "-p", "You are q4.gguf, be brief"},
+		},
+		{
+			name:     "single quotes are literal",
+			template: `llama-cli --chat-template '{ngl} stays'`,
+			want:     []string{"llama-cli", "--chat-template", "{ngl} stays"},
+		},
+		{
+			name:     "backslash escapes",
+			template: `llama-cli --log-file /tmp/my\ log.txt \[x\]`,
+			want:     []string{"llama-cli", "--log-file", "/tmp/my log.txt", "[x]"},
+		},
+		{
+			name:     "backslashes in double quotes",
+			template: `llama-server -m "C:\models\{model_name}" "--regex=\d+" "say \"hi\" \\ \$HOME"`,
+			want:     []string{"llama-server", "-m", `C:\models\q4.gguf`, `--regex=\d+`, `say "hi" \ $HOME`},
+		},
+		{
+			name:     "optional section dropped for zero",
+			template: "llama-server -m {model_path} [-c {ctx_size}] -ngl {ngl}",
+			want:     []string{"llama-server", "-m", "/models/My Model/q4.gguf", "-ngl", "99"},
+		},
+		{
+			name:     "optional section kept for non-zero",
+			template: "llama-server [-ngl {ngl}] [--host {host}]",
+			want:     []string{"llama-server", "-ngl", "99", "--host", "0.0.0.0"},
+		},
+		{
+			name:     "optional section dropped for empty",
+			template: "llama-server [--api-key {empty}]",
+			want:     []string{"llama-server"},
+		},
+		{
+			name:     "nested optional sections",
+			template: "llama-server [--host {host} [-c {ctx_size}]]",
+			want:     []string{"llama-server", "--host", "0.0.0.0"},
+		},
+		{
+			name:     "unquoted empty expansion is dropped",
+			template: "llama-server {empty} -ngl {ngl}",
+			want:     []string{"llama-server", "-ngl", "99"},
+		},
+		{
+			name:     "quoted empty expansion is kept",
+			template: `llama-server --alias "{empty}"`,
+			want:     []string{"llama-server", "--alias", ""},
+		},
+		{
+			name:     "placeholder glued to text",
+			template: "llama-server --model={model_name} --port=80{ctx_size}",
+			want:     []string{"llama-server", "--model=q4.gguf", "--port=800"},
+		},
+		{
+			name:     "braces that are not placeholders",
+			template: `llama-server --json-schema {} {not closed`,
+			want:     []string{"llama-server", "--json-schema", "{}", "{not", "closed"},
+		},
+		{
+			name:     "quoted bracket is literal",
+			template: `llama-server "[" "]"`,
+			want:     []string{"llama-server", "[", "]"},
+		},
+		{
+			name:     "optional section inside an argument",
+			template: "llama-server -hf org/repo[:{model_name}] --alias x[-{ctx_size}]",
+			want:     []string{"llama-server", "-hf", "org/repo:q4.gguf", "--alias", "x"},
+		},
+		{
+			name:     "nested optional sections inside an argument",
+			template: "llama-server --tag a[-{ngl}[-{empty}]]",
+			want:     []string{"llama-server", "--tag", "a-99"},
+		},
+		{name: "unknown placeholder", template: "llama-server {port}", wantErr: true},
+		{name: "whitespace in inline section", template: "llama-server -hf repo[:{ngl} x]", wantErr: true},
+		{name: "unterminated inline section", template: "llama-server -hf repo[:{ngl}", wantErr: true},
+		{name: "unknown placeholder in optional section", template: "llama-server [--port {port}]", wantErr: true},
+		{name: "unterminated quote", template: `llama-server "oops`, wantErr: true},
+		{name: "unterminated section", template: "llama-server [-c {ctx_size}", wantErr: true},
+		{name: "stray closing bracket", template: "llama-server ]", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandTemplate(tt.template, vars)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQuoteArgs(t *testing.T) {
+	args := []string{"llama-server", "-m", "/models/My Model/q4.gguf", "-p", "it's", "", "--port=8080"}
+	assert.Equal(t, `llama-server -m '/models/My Model/q4.gguf' -p 'it'\''s' '' --port=8080`, QuoteArgs(args))
+}
+
+func TestProcessManager_TemplateVars(t *testing.T) {
+	pm := NewProcessManager(nil)
+	pm.SetTemplates(Templates{
+		Server: "llama-server -m {model_path} [--port {port}] [-c {ctx_size}]",
+		CLI:    "llama-cli -m {model_path} --model_path {model_path}",
+	})
+	pm.SetTemplateVars(map[string]string{"port": "8081", "model_path": "ignored"})
+
+	args, err := pm.Args(LaunchSpec{Mode: ModeServer, ModelPath: "/models/a b.gguf", ModelName: "a b.gguf", NGL: 99, CtxSize: 4096})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"llama-server", "-m", "/models/a b.gguf", "--port", "8081", "-c", "4096"}, args)
+
+	args, err = pm.Args(LaunchSpec{Mode: ModeCLI, ModelPath: "/models/a.gguf", ModelName: "a.gguf"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"llama-cli", "-m", "/models/a.gguf", "--model_path", "/models/a.gguf"}, args)
+}
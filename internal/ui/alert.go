@@ -0,0 +1,49 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// showAlert opens a modal with a message that needs the user's attention,
+// such as a repo that is gated
+func (m *Model) showAlert(title, text string) {
+	m.alertTitle = title
+	m.alertText = text
+	m.showAlertModal = true
+	m.output += title + ": " + text + "\n"
+}
+
+// updateAlertModal closes the alert on Esc, Enter or q
+func (m *Model) updateAlertModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "enter", "q":
+		m.showAlertModal = false
+	}
+	return m, nil
+}
+
+// renderAlertModal renders the alert modal
+func (m *Model) renderAlertModal(base string, width, height int) string {
+	modalWidth := 60
+
+	modalContent := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Bold(true).Render(m.alertTitle),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2")).Width(modalWidth-4).Render(m.alertText),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render("Press Esc to close"),
+	)
+
+	modal := lipgloss.NewStyle().
+		Width(modalWidth).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#FF5555")).
+		Background(lipgloss.Color("#282A36")).
+		Render(modalContent)
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("#000000")))
+}
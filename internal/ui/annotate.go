@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	hfmodels "github.com/Megatherium/hf-go"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+	"lloader/internal/models"
+)
+
+// annotationTarget is the model or repo a favorite, tag or note belongs
+// to: a local model file by path, or a HuggingFace repo by ID
+type annotationTarget struct {
+	path string
+	repo string
+}
+
+func (t annotationTarget) label() string {
+	if t.repo != "" {
+		return t.repo
+	}
+	return t.path
+}
+
+// selectedTarget returns what the active tab has selected
+func (m *Model) selectedTarget() (annotationTarget, bool) {
+	switch {
+	case m.activeTab == 0 && len(m.models) > 0:
+		return annotationTarget{path: m.models[m.selected].Path}, true
+	case m.activeTab == 1 && len(m.hfModels) > 0:
+		return annotationTarget{repo: m.hfModels[m.hfSelected].ID}, true
+	}
+	return annotationTarget{}, false
+}
+
+func (m *Model) annotation(t annotationTarget) app.Annotation {
+	if t.repo != "" {
+		return m.userData.Repo(t.repo)
+	}
+	return m.userData.Model(t.path)
+}
+
+// setAnnotation stores an annotation, saves the user data and re-pins
+// the lists
+func (m *Model) setAnnotation(t annotationTarget, a app.Annotation) {
+	if m.userData.ReadOnly() {
+		m.showAlert("User data not saved", fmt.Sprintf("%s could not be read at startup. Fix or remove it and restart lloader to change favorites, tags and notes.", m.userData.Path()))
+		return
+	}
+	if t.repo != "" {
+		m.userData.SetRepo(t.repo, a)
+	} else {
+		m.userData.SetModel(t.path, a)
+	}
+	if err := m.userData.Save(); err != nil {
+		m.output += fmt.Sprintf("Failed to save %s: %v\n", m.userData.Path(), err)
+		if m.logger != nil {
+			m.logger.Warn("Failed to save user data", zap.String("file", m.userData.Path()), zap.Error(err))
+		}
+	}
+	m.applyView()
+	m.applyHFView()
+}
+
+// toggleFavorite stars or unstars the selected model or repo
+func (m *Model) toggleFavorite() {
+	target, ok := m.selectedTarget()
+	if !ok {
+		return
+	}
+	a := m.annotation(target)
+	a.Favorite = !a.Favorite
+	m.setAnnotation(target, a)
+}
+
+// openAnnotateModal edits the tags and note of the selected model or repo
+func (m *Model) openAnnotateModal() {
+	target, ok := m.selectedTarget()
+	if !ok {
+		return
+	}
+	a := m.annotation(target)
+	m.annotateTarget = target
+	m.tagsInput.SetValue(strings.Join(a.Tags, ", "))
+	m.noteInput.SetValue(a.Note)
+	m.annotateFocusIdx = 0
+	m.tagsInput.Focus()
+	m.noteInput.Blur()
+	m.showAnnotateModal = true
+}
+
+func (m *Model) closeAnnotateModal() {
+	m.showAnnotateModal = false
+	m.tagsInput.Blur()
+	m.noteInput.Blur()
+}
+
+// updateAnnotateModal handles keys in the tags and note modal
+func (m *Model) updateAnnotateModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "esc":
+		m.closeAnnotateModal()
+		return m, nil
+	case "enter":
+		a := m.annotation(m.annotateTarget)
+		a.Tags = app.ParseTags(m.tagsInput.Value())
+		a.Note = strings.TrimSpace(m.noteInput.Value())
+		m.setAnnotation(m.annotateTarget, a)
+		m.closeAnnotateModal()
+		return m, nil
+	case "tab", "shift+tab", "down", "up":
+		m.annotateFocusIdx = 1 - m.annotateFocusIdx
+		if m.annotateFocusIdx == 0 {
+			m.tagsInput.Focus()
+			m.noteInput.Blur()
+		} else {
+			m.tagsInput.Blur()
+			m.noteInput.Focus()
+		}
+		return m, nil
+	}
+
+	if m.annotateFocusIdx == 0 {
+		m.tagsInput, cmd = m.tagsInput.Update(msg)
+	} else {
+		m.noteInput, cmd = m.noteInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// renderAnnotateModal renders the tags and note modal
+func (m *Model) renderAnnotateModal(base string, width, height int) string {
+	modalWidth := 60
+
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2"))
+	focusedLabel := lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+
+	tagsLabel := labelStyle.Render("Tags (comma-separated):")
+	noteLabel := labelStyle.Render("Note:")
+	if m.annotateFocusIdx == 0 {
+		tagsLabel = focusedLabel.Render("> Tags (comma-separated):")
+	} else {
+		noteLabel = focusedLabel.Render("> Note:")
+	}
+
+	modalContent := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9")).Bold(true).Render("Tags & Note"),
+		dimStyle.Render(truncate(m.annotateTarget.label(), modalWidth-6)),
+		"",
+		tagsLabel,
+		m.tagsInput.View(),
+		"",
+		noteLabel,
+		m.noteInput.View(),
+		"",
+		dimStyle.Render("Enter: Save | Esc: Cancel | Tab: Switch"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Width(modalWidth).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#FF79C6")).
+		Background(lipgloss.Color("#282A36"))
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, modalStyle.Render(modalContent),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("#000000")))
+}
+
+// writeAnnotation adds the tags and note lines to an info modal
+func writeAnnotation(info *strings.Builder, a app.Annotation, labelStyle, infoStyle lipgloss.Style) {
+	if a.Favorite {
+		info.WriteString(labelStyle.Render("Favorite: "))
+		info.WriteString(infoStyle.Render("★") + "\n")
+	}
+	if len(a.Tags) > 0 {
+		info.WriteString(labelStyle.Render("Tags: "))
+		info.WriteString(infoStyle.Render(formatTags(a.Tags)) + "\n")
+	}
+	if a.Note != "" {
+		info.WriteString(labelStyle.Render("Note: "))
+		info.WriteString(infoStyle.Render(a.Note) + "\n")
+	}
+}
+
+// formatTags renders tags as "#coding #vision"
+func formatTags(tags []string) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = "#" + tag
+	}
+	return strings.Join(parts, " ")
+}
+
+// favoriteMark prefixes favorites with a star
+func favoriteMark(a app.Annotation, label string) string {
+	if a.Favorite {
+		return "★ " + label
+	}
+	return label
+}
+
+// filterLocal keeps the models carrying all tags and moves favorites to
+// the top, otherwise keeping the order
+func (m *Model) filterLocal(list []models.Model, tags []string) []models.Model {
+	list = slices.DeleteFunc(list, func(model models.Model) bool {
+		return !m.userData.Model(model.Path).HasTags(tags)
+	})
+	slices.SortStableFunc(list, func(a, b models.Model) int {
+		return app.FavoritesFirst(m.userData.Model(a.Path), m.userData.Model(b.Path))
+	})
+	return list
+}
+
+// applyHFView rebuilds the HF list from the last results: repos missing
+// one of the search's #tags are hidden and favorites come first. The
+// selection stays on the selected repo.
+func (m *Model) applyHFView() {
+	var id string
+	if m.hfSelected < len(m.hfModels) {
+		id = m.hfModels[m.hfSelected].ID
+	}
+
+	list := slices.DeleteFunc(slices.Clone(m.hfResults), func(model hfmodels.Model) bool {
+		return !m.userData.Repo(model.ID).HasTags(m.hfTags)
+	})
+	slices.SortStableFunc(list, func(a, b hfmodels.Model) int {
+		return app.FavoritesFirst(m.userData.Repo(a.ID), m.userData.Repo(b.ID))
+	})
+	m.hfModels = list
+
+	m.hfSelected = 0
+	for i, model := range m.hfModels {
+		if model.ID == id {
+			m.hfSelected = i
+			break
+		}
+	}
+}
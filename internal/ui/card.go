@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"lloader/internal/hf"
+)
+
+// HFReadmeMsg carries the model card of a repo
+type HFReadmeMsg struct {
+	ModelID string
+	Readme  hf.Readme
+	Info    hf.Info
+	Err     error
+}
+
+// fetchReadme fetches the model card of a repo for the info view
+func (m *Model) fetchReadme(modelID string) tea.Cmd {
+	m.cardID = modelID
+	m.cardLoading = true
+	m.cardText = ""
+	m.cardErr = nil
+	m.cardInfo = hf.Info{}
+	m.cardWidth = 0
+	return func() tea.Msg {
+		readme, info, err := m.hfCache.GetReadme(modelID)
+		return HFReadmeMsg{ModelID: modelID, Readme: readme, Info: info, Err: err}
+	}
+}
+
+// setReadme takes a fetched model card unless another repo's info was
+// opened since
+func (m *Model) setReadme(msg HFReadmeMsg) {
+	if msg.ModelID != m.cardID {
+		return
+	}
+	m.cardLoading = false
+	m.cardText = msg.Readme.Text
+	m.cardErr = msg.Err
+	m.cardInfo = msg.Info
+	m.cardWidth = 0
+	m.cardView.GotoTop()
+}
+
+// renderCard sizes the model card view and renders it, rendering the
+// markdown again when the width changed
+func (m *Model) renderCard(width, height int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+
+	m.cardView.Width = width
+	m.cardView.Height = max(3, height)
+	if width != m.cardWidth {
+		var content string
+		switch {
+		case m.cardLoading:
+			content = dimStyle.Render("Loading model card...")
+		case errors.Is(m.cardErr, hf.ErrNoReadme):
+			content = dimStyle.Render("This repo has no model card.")
+		case m.cardErr != nil:
+			content = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Width(width).
+				Render(fmt.Sprintf("Could not load the model card: %v", m.cardErr))
+		default:
+			content = renderMarkdown(m.cardText, width)
+		}
+		m.cardView.SetContent(content)
+		m.cardWidth = width
+	}
+	return m.cardView.View()
+}
@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+	"lloader/internal/download"
+	"lloader/internal/models"
+)
+
+// DownloadsUpdatedMsg carries the download queue after it changed
+type DownloadsUpdatedMsg struct {
+	Items []download.Item
+}
+
+// waitForDownloadsCmd blocks until the download queue changes
+func (m *Model) waitForDownloadsCmd() tea.Cmd {
+	if m.downloads == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		<-m.downloads.Updates()
+		return DownloadsUpdatedMsg{Items: m.downloads.Items()}
+	}
+}
+
+// queueDownloads adds quants of a repo to the download queue
+func (m *Model) queueDownloads(repo string, quants []string) {
+	for _, quant := range quants {
+		if m.downloads.Add(repo, quant) {
+			m.output += fmt.Sprintf("Queued %s %s for download to %s\n", repo, quant, m.downloads.Dir())
+		} else {
+			m.output += fmt.Sprintf("%s %s is already queued\n", repo, quant)
+		}
+	}
+	m.setDownloads(m.downloads.Items())
+}
+
+// setDownloads takes a new snapshot of the queue and reports items that
+// finished since the last one. Without a watcher the models directories
+// are rescanned so finished downloads show up in Local.
+func (m *Model) setDownloads(items []download.Item) tea.Cmd {
+	var id string
+	if m.downloadSelected < len(m.downloadItems) {
+		id = m.downloadItems[m.downloadSelected].ID()
+	}
+	m.downloadItems = items
+	m.downloadSelected = 0
+	for i, it := range items {
+		if it.ID() == id {
+			m.downloadSelected = i
+		}
+	}
+
+	rescan := false
+	for _, it := range items {
+		if m.downloadStates[it.ID()] == it.State {
+			continue
+		}
+		m.downloadStates[it.ID()] = it.State
+		switch it.State {
+		case download.StateDone:
+			m.output += fmt.Sprintf("Downloaded %s %s:\n", it.Repo, it.Quant)
+			for _, path := range it.Paths {
+				m.output += "  " + path + "\n"
+			}
+			rescan = true
+		case download.StateFailed:
+			m.output += fmt.Sprintf("Download of %s %s failed: %s\n", it.Repo, it.Quant, it.Err)
+			m.alertAccess(errors.New(it.Err), it.Repo)
+			if m.logger != nil {
+				m.logger.Warn("Download failed", zap.String("repo", it.Repo), zap.String("quant", it.Quant), zap.String("error", it.Err))
+			}
+		}
+	}
+	if !rescan || m.modelUpdates != nil {
+		return nil
+	}
+	return func() tea.Msg {
+		list, err := models.DiscoverModels(m.config, m.logger)
+		if err != nil {
+			return nil
+		}
+		return ModelsUpdatedMsg{Models: list}
+	}
+}
+
+// updateDownloadsTab handles the Downloads tab's keys; ok is false for
+// keys it leaves to the global handler
+func (m *Model) updateDownloadsTab(msg tea.KeyMsg) (cmd tea.Cmd, ok bool) {
+	if len(m.downloadItems) == 0 {
+		return nil, false
+	}
+	it := m.downloadItems[m.downloadSelected]
+
+	switch msg.String() {
+	case "up":
+		m.downloadSelected = (m.downloadSelected + len(m.downloadItems) - 1) % len(m.downloadItems)
+	case "down":
+		m.downloadSelected = (m.downloadSelected + 1) % len(m.downloadItems)
+	case " ":
+		if it.State == download.StateQueued || it.State == download.StateDownloading {
+			m.downloads.Pause(it.ID())
+		} else {
+			m.downloads.Resume(it.ID())
+		}
+	case "c":
+		m.downloads.Cancel(it.ID())
+	case "shift+up", "K":
+		m.downloads.Move(it.ID(), -1)
+	case "shift+down", "J":
+		m.downloads.Move(it.ID(), 1)
+	case "C":
+		m.downloads.ClearFinished()
+	default:
+		return nil, false
+	}
+	return m.setDownloads(m.downloads.Items()), true
+}
+
+// renderDownloads renders the Downloads tab
+func (m *Model) renderDownloads(width int, modelStyle, selectedStyle lipgloss.Style) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
+
+	var b strings.Builder
+	if len(m.downloadItems) == 0 {
+		b.WriteString(dimStyle.Render("Nothing queued. Pick quants with") + "\n")
+		b.WriteString(dimStyle.Render("space and d in the HuggingFace tab.") + "\n")
+		return b.String()
+	}
+	b.WriteString(dimStyle.Render("space pause/resume · c cancel · K/J move · C clear") + "\n")
+
+	labelWidth := width - 8
+	for i, it := range m.downloadItems {
+		label := truncate(it.Repo+" "+it.Quant, labelWidth)
+		if i == m.downloadSelected {
+			b.WriteString(selectedStyle.Render(" > " + label))
+		} else {
+			b.WriteString(modelStyle.Render("   " + label))
+		}
+		b.WriteString("\n")
+
+		status := "     " + downloadStatus(it, labelWidth-2)
+		if it.State == download.StateFailed {
+			b.WriteString(errStyle.Render(truncate(status, labelWidth+3)) + "\n")
+		} else {
+			b.WriteString(dimStyle.Render(status) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// downloadStatus renders the state line of a queue item, with a progress
+// bar, speed and ETA while downloading
+func downloadStatus(it download.Item, width int) string {
+	percent := fmt.Sprintf("%.0f%%", it.Fraction()*100)
+	switch it.State {
+	case download.StateDone:
+		return "done, " + formatBytes(it.Total)
+	case download.StateFailed:
+		return "failed: " + it.Err
+	case download.StateCanceled:
+		return "canceled"
+	case download.StatePaused:
+		return "paused at " + percent
+	case download.StateQueued:
+		if it.Done > 0 {
+			return "queued at " + percent
+		}
+		return "queued"
+	}
+
+	if it.Total == 0 {
+		return "starting..."
+	}
+	detail := fmt.Sprintf(" %s %s", percent, formatSpeed(it.Speed))
+	if eta := it.ETA(); eta > 0 {
+		detail += " " + formatETA(eta)
+	}
+	if len(it.Files) > 1 {
+		detail += fmt.Sprintf(" %d/%d", it.FileIndex+1, len(it.Files))
+	}
+	barWidth := width - len(detail)
+	if barWidth < 5 {
+		return strings.TrimSpace(detail)
+	}
+	filled := int(it.Fraction() * float64(barWidth))
+	return strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled) + detail
+}
+
+// downloadSummary is the status bar's note on running downloads, "" if
+// none run
+func (m *Model) downloadSummary() string {
+	var active, queued int
+	var speed float64
+	for _, it := range m.downloadItems {
+		switch it.State {
+		case download.StateDownloading:
+			active++
+			speed += it.Speed
+		case download.StateQueued:
+			queued++
+		}
+	}
+	if active == 0 {
+		return ""
+	}
+	summary := fmt.Sprintf("| ↓ %d %s", active, formatSpeed(speed))
+	if queued > 0 {
+		summary += fmt.Sprintf(", %d queued", queued)
+	}
+	return summary + " (3 to view) "
+}
+
+// formatSpeed renders a transfer rate
+func formatSpeed(bytesPerSecond float64) string {
+	return formatBytes(int64(bytesPerSecond)) + "/s"
+}
+
+// formatETA renders a remaining time to the second, or minute if long
+func formatETA(d time.Duration) string {
+	if d >= time.Hour {
+		return d.Round(time.Minute).String()
+	}
+	return d.Round(time.Second).String()
+}
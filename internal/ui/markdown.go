@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	mdImage     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink      = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	mdCode      = regexp.MustCompile("`([^`]+)`")
+	mdBold      = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdTag       = regexp.MustCompile(`<!--.*?-->|<[^>]+>`)
+	mdHeading   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	mdBullet    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdNumbered  = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	mdRule      = regexp.MustCompile(`^\s*([-*_])(\s*([-*_]))+\s*$`)
+	mdTableRule = regexp.MustCompile(`^\s*\|?[\s:|-]+\|[\s:|-]*$`)
+)
+
+// renderMarkdown renders a model card for the terminal, wrapped to width.
+// It covers what cards use: headings, paragraphs, lists, quotes, code
+// blocks, tables, emphasis and links. HTML tags are dropped.
+func renderMarkdown(text string, width int) string {
+	width = max(width, 20)
+	h1Style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF79C6")).Bold(true)
+	h2Style := lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9")).Bold(true)
+	h3Style := lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Bold(true)
+	codeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2"))
+
+	var out []string
+	var paragraph []string
+	blank := func() {
+		if len(out) > 0 && out[len(out)-1] != "" {
+			out = append(out, "")
+		}
+	}
+	// wrap adds s wrapped to width, the first line after prefix and the
+	// others indented to match it
+	wrap := func(prefix, s string, style lipgloss.Style) {
+		indent := strings.Repeat(" ", lipgloss.Width(prefix))
+		wrapped := style.Width(width - len(indent)).Render(renderInline(s))
+		for i, line := range strings.Split(wrapped, "\n") {
+			if i == 0 {
+				out = append(out, prefix+line)
+			} else {
+				out = append(out, indent+line)
+			}
+		}
+	}
+	var table [][]string
+	flush := func() {
+		if len(paragraph) > 0 {
+			wrap("", strings.Join(paragraph, " "), textStyle)
+			paragraph = nil
+		}
+		if len(table) > 0 {
+			for _, line := range renderTable(table) {
+				out = append(out, textStyle.Render(truncate(line, width)))
+			}
+			table = nil
+		}
+	}
+
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			if !inCode {
+				blank()
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, codeStyle.Render("  "+truncate(strings.ReplaceAll(line, "\t", "    "), width-2)))
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") {
+			if len(paragraph) > 0 {
+				flush()
+			}
+			if mdTableRule.MatchString(trimmed) {
+				// The rule under the header row is drawn by renderTable
+				table = append(table, nil)
+				continue
+			}
+			cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+			for i, cell := range cells {
+				cells[i] = stripInline(strings.TrimSpace(cell))
+			}
+			table = append(table, cells)
+			continue
+		}
+
+		if len(table) > 0 {
+			flush()
+		}
+
+		trimmed = strings.TrimSpace(mdTag.ReplaceAllString(trimmed, ""))
+		switch {
+		case trimmed == "":
+			// Lines of only HTML keep paragraphs together like blank ones
+			flush()
+			blank()
+		case mdHeading.MatchString(trimmed):
+			flush()
+			blank()
+			match := mdHeading.FindStringSubmatch(trimmed)
+			style := h3Style
+			switch len(match[1]) {
+			case 1:
+				style = h1Style
+			case 2:
+				style = h2Style
+			}
+			wrap("", stripInline(match[2]), style)
+			out = append(out, "")
+		case mdRule.MatchString(trimmed):
+			flush()
+			out = append(out, dimStyle.Render(strings.Repeat("─", width)))
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			wrap(dimStyle.Render("│ "), strings.TrimSpace(strings.TrimLeft(trimmed, "> ")), dimStyle)
+		case mdBullet.MatchString(line):
+			flush()
+			match := mdBullet.FindStringSubmatch(line)
+			wrap(listIndent(match[1])+"• ", match[2], textStyle)
+		case mdNumbered.MatchString(line):
+			flush()
+			match := mdNumbered.FindStringSubmatch(line)
+			wrap(listIndent(match[1])+match[2]+" ", match[3], textStyle)
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
+
+// renderTable lines up the cells of a table; nil rows are rules
+func renderTable(rows [][]string) []string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		parts := make([]string, len(widths))
+		for j, w := range widths {
+			switch {
+			case row == nil:
+				parts[j] = strings.Repeat("─", w)
+			case j < len(row):
+				parts[j] = row[j] + strings.Repeat(" ", w-lipgloss.Width(row[j]))
+			default:
+				parts[j] = strings.Repeat(" ", w)
+			}
+		}
+		if row == nil {
+			lines[i] = strings.Join(parts, "─┼─")
+		} else {
+			lines[i] = strings.TrimRight(strings.Join(parts, " │ "), " ")
+		}
+	}
+	return lines
+}
+
+// listIndent indents nested list items by two spaces a level
+func listIndent(leading string) string {
+	return strings.Repeat("  ", 1+len(strings.ReplaceAll(leading, "\t", "  "))/2)
+}
+
+// renderInline styles code spans and bold text and shows links as their
+// text
+func renderInline(s string) string {
+	codeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C"))
+	boldStyle := lipgloss.NewStyle().Bold(true)
+
+	s = mdTag.ReplaceAllString(s, "")
+	s = mdImage.ReplaceAllString(s, "[image: $1]")
+	s = mdLink.ReplaceAllString(s, "$1")
+	s = mdCode.ReplaceAllStringFunc(s, func(code string) string {
+		return codeStyle.Render(strings.Trim(code, "`"))
+	})
+	return mdBold.ReplaceAllStringFunc(s, func(bold string) string {
+		return boldStyle.Render(strings.Trim(bold, "*_"))
+	})
+}
+
+// stripInline removes inline markup where styling is not wanted
+func stripInline(s string) string {
+	s = mdTag.ReplaceAllString(s, "")
+	s = mdImage.ReplaceAllString(s, "[image: $1]")
+	s = mdLink.ReplaceAllString(s, "$1")
+	s = mdCode.ReplaceAllString(s, "$1")
+	return mdBold.ReplaceAllString(s, "$1$2")
+}
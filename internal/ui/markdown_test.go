@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+var ansi = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// plain drops styling and the padding lipgloss adds to wrapped lines
+func plain(s string) string {
+	lines := strings.Split(ansi.ReplaceAllString(s, ""), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{
+			name:  "empty",
+			text:  "",
+			width: 40,
+			want:  "",
+		},
+		{
+			name:  "only blank lines",
+			text:  "\n\r\n\n",
+			width: 40,
+			want:  "",
+		},
+		{
+			name:  "fenced code is kept verbatim",
+			text:  "Intro\n```go\nfunc main() {\n\tfmt.Println(\"# not a heading\")\n}\n```\nAfter",
+			width: 40,
+			want:  "Intro\n\n  func main() {\n      fmt.Println(\"# not a heading\")\n  }\nAfter",
+		},
+		{
+			name:  "long code lines are cut",
+			text:  "~~~\nllama-server -m model.gguf --ctx-size 8192\n~~~",
+			width: 30,
+			want:  "  llama-server -m model.ggu...",
+		},
+		{
+			name:  "nested lists",
+			text:  "- one\n- two\n  - nested **bold**\n    - deeper\n1. first\n2) second",
+			width: 40,
+			want:  "  • one\n  • two\n    • nested bold\n      • deeper\n  1. first\n  2) second",
+		},
+		{
+			name:  "list items wrap under their text",
+			text:  "- a list item long enough to wrap onto the next line",
+			width: 30,
+			want:  "  • a list item long enough to\n    wrap onto the next line",
+		},
+		{
+			name:  "paragraphs are joined and wrapped",
+			text:  "A paragraph split\nover two lines.\n\nAnother one.",
+			width: 40,
+			want:  "A paragraph split over two lines.\n\nAnother one.",
+		},
+		{
+			name:  "long words are broken",
+			text:  "averyveryveryverylongwordthatdoesnotfitintothewidth and more",
+			width: 30,
+			want:  "averyveryveryverylongwordthatd\noesnotfitintothewidth and more",
+		},
+		{
+			name:  "headings and inline markup",
+			text:  "# Title #\nSome `code` and [a link](https://example.com \"title\").",
+			width: 40,
+			want:  "Title\n\nSome code and a link.",
+		},
+		{
+			name:  "tables",
+			text:  "Quants:\n| Quant | Size |\n|:--|--:|\n| Q4_K_M | 4.9 GB |\n| Q8_0 | 8.5 GB |\nDone.",
+			width: 40,
+			want:  "Quants:\nQuant  │ Size\n───────┼───────\nQ4_K_M │ 4.9 GB\nQ8_0   │ 8.5 GB\nDone.",
+		},
+		{
+			name:  "quotes",
+			text:  "> quoted line that is long enough to wrap around",
+			width: 30,
+			want:  "│ quoted line that is long\n  enough to wrap around",
+		},
+		{
+			name:  "html, rules and images",
+			text:  "<p align=\"center\">\n<img src=\"logo.png\">\n</p>\n\n---\n![logo](logo.png)",
+			width: 20,
+			want:  strings.Repeat("─", 20) + "\n[image: logo]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderMarkdown(tt.text, tt.width)
+			assert.Equal(t, tt.want, plain(got))
+			for _, line := range strings.Split(got, "\n") {
+				assert.LessOrEqual(t, lipgloss.Width(line), tt.width, "line %q is too wide", line)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdown_MinimumWidth(t *testing.T) {
+	got := plain(renderMarkdown("some words that need wrapping", 5))
+	for _, line := range strings.Split(got, "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 20, "narrow panes still get 20 columns")
+	}
+	assert.Equal(t, "some words that need\nwrapping", got)
+}
@@ -3,16 +3,21 @@ package ui
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"lloader/internal/app"
+	"lloader/internal/download"
+	"lloader/internal/hf"
+	"lloader/internal/models"
 	"lloader/internal/process"
 
 	hfmodels "github.com/Megatherium/hf-go"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"go.uber.org/zap"
@@ -21,7 +26,25 @@ import (
 
 // OutputMsg is a message type for output from processes
 type OutputMsg struct {
-	Output string
+	Instance string
+	Output   string
+}
+
+// ProcessStoppedMsg reports that an instance has been shut down
+type ProcessStoppedMsg struct {
+	Instance string
+	Reason   string
+}
+
+// ProcessExitedMsg reports that an instance's process ended on its own
+type ProcessExitedMsg struct {
+	Status process.ExitStatus
+}
+
+// ModelsUpdatedMsg carries the rediscovered local models after a change
+// in the models directories
+type ModelsUpdatedMsg struct {
+	Models []models.Model
 }
 
 // CheckOutputMsg is a message to check for new output
@@ -32,7 +55,13 @@ type InitMsg struct{}
 
 // HFSearchResultMsg contains search results from HuggingFace
 type HFSearchResultMsg struct {
+	Options hf.SearchOptions
+	// Page is the URL of the page loaded, "" for the first
+	Page string
+	// Next is the URL of the following page, "" after the last
+	Next   string
 	Models []hfmodels.Model
+	Info   hf.Info
 	Err    error
 }
 
@@ -40,24 +69,32 @@ type HFSearchResultMsg struct {
 type HFQuantsResultMsg struct {
 	ModelID string
 	Quants  []string
+	Info    hf.Info
 	Err     error
 }
 
 // HFModelDetailsMsg contains detailed model information
 type HFModelDetailsMsg struct {
+	ModelID string
 	Details *hfmodels.ModelDetails
+	Info    hf.Info
 	Err     error
 }
 
 // Model represents the application state
 type Model struct {
-	models       []string
+	// allModels is everything discovered, models the filtered and sorted
+	// view of it that the Local tab shows and selected indexes
+	allModels    []models.Model
+	models       []models.Model
+	modelUpdates <-chan []models.Model
 	selected     int
 	output       string
 	quit         bool
 	processMgr   *process.ProcessManager
 	focusRight   bool
-	outputChan   chan string
+	outputChan   chan OutputMsg
+	exitChan     chan process.ExitStatus
 	logger       *zap.Logger
 	config       *app.Config
 	windowWidth  int
@@ -67,6 +104,9 @@ type Model struct {
 	// Session overrides (reset each run)
 	sessionNGL     int
 	sessionCtxSize int
+	// sessionOverridden is set once the override modal was saved; the
+	// session values then win over launch profiles
+	sessionOverridden bool
 
 	// Modal state
 	showModal     bool
@@ -78,37 +118,118 @@ type Model struct {
 	cliInputBuffer string
 	cliMode        bool
 
-	// Tab state: 0 = Local, 1 = HuggingFace
+	// Running instances: output per instance, "" is the main log
+	instanceOutput  map[string]string
+	focusedInstance string
+
+	// Process list modal
+	showProcModal bool
+	procSelected  int
+
+	// Tab state: 0 = Local, 1 = HuggingFace, 2 = Downloads
 	activeTab int
 
+	// Local list filter, sort order and scroll position
+	localFilterInput   textinput.Model
+	localFilterFocused bool
+	sortMode           models.SortMode
+	listOffset         int
+	listPageSize       int // model rows that fit, as of the last render
+
 	// HuggingFace search state
 	hfSearchInput   textinput.Model
 	hfSearchFocused bool
+	hfResults       []hfmodels.Model // last search results, unfiltered
+	hfTags          []string         // #tags of the last search
 	hfModels        []hfmodels.Model
 	hfSelected      int
 	hfSearching     bool
-	hfClient        *hfmodels.Client
+	hfInfo          hf.Info          // how current the results are
+	hfOptions       hf.SearchOptions // the search shown
+	hfNext          string           // URL of the next page, "" after the last
+	hfLoadingMore   bool
+	hfListOffset    int // first result shown
+	hfCache         *hf.Cache
+	hfToken         string
+	hfEndpoint      string
+
+	// Search options modal
+	showSearchOptions bool
+	searchOptFocus    int
+	searchOptDraft    app.SearchFilters
+	authorInput       textinput.Model
+	licenseInput      textinput.Model
 
 	// Quantization selection modal
 	showQuantModal  bool
 	quantSelected   int
 	selectedHFModel *hfmodels.Model
 	availableQuants []string
+	quantsInfo      hf.Info
+	markedQuants    map[string]bool // picked for download with space
 	loadingQuants   bool
 
 	// Model info modal
 	showInfoModal  bool
 	modelDetails   *hfmodels.ModelDetails
+	detailsInfo    hf.Info
+	localDetails   *models.Model
 	loadingDetails bool
 
+	// Model card in the HF info view
+	cardView    viewport.Model
+	cardID      string
+	cardText    string
+	cardErr     error
+	cardInfo    hf.Info
+	cardLoading bool
+	cardWidth   int // width the card was rendered for, 0 to render again
+
 	// No quants confirmation modal
 	showNoQuantModal bool
+
+	// Favorites, tags and notes, and the modal editing them
+	userData          *app.UserData
+	showAnnotateModal bool
+	annotateTarget    annotationTarget
+	annotateFocusIdx  int // 0 = tags, 1 = note
+	tagsInput         textinput.Model
+	noteInput         textinput.Model
+
+	// Download queue, as of its last update, and the Downloads tab
+	downloads        *download.Queue
+	downloadItems    []download.Item
+	downloadStates   map[string]download.State // last reported per item
+	downloadSelected int
+
+	// Alert modal for errors that need attention
+	showAlertModal bool
+	alertTitle     string
+	alertText      string
 }
 
 // NewModel creates a new model
-func NewModel(models []string, config *app.Config, logger *zap.Logger) *Model {
+func NewModel(localModels []models.Model, config *app.Config, logger *zap.Logger) *Model {
 	pm := process.NewProcessManager(logger)
-	pm.SetTemplates(config.ServerTemplate, config.CLITemplate)
+	pm.SetTemplates(process.Templates{
+		Server:   config.ServerTemplate,
+		CLI:      config.CLITemplate,
+		ServerHF: config.ServerHFTemplate,
+		CLIHF:    config.CLIHFTemplate,
+	})
+	pm.SetTemplateVars(config.TemplateVars)
+	exitChan := make(chan process.ExitStatus, 16)
+	pm.SetExitHandler(func(status process.ExitStatus) {
+		exitChan <- status
+	})
+	if err := pm.SetCLIPTY(config.CLIPTY); err != nil {
+		logger.Warn("Ignoring cli_pty", zap.Error(err))
+	}
+	if sig, err := process.ParseSignal(config.StopSignal); err != nil {
+		logger.Warn("Invalid stop_signal, keeping default", zap.Error(err))
+	} else {
+		pm.SetShutdown(sig, config.StopTimeout)
+	}
 
 	nglInput := textinput.New()
 	nglInput.Placeholder = "99"
@@ -127,33 +248,280 @@ func NewModel(models []string, config *app.Config, logger *zap.Logger) *Model {
 	hfSearch.CharLimit = 100
 	hfSearch.Width = 30
 
-	return &Model{
-		models:         models,
-		selected:       0,
-		output:         "Ready. Select a model and press Enter for server, c for cli, e for config.\nPress 1/2 to switch tabs. In HF tab, press / to search.",
-		outputChan:     make(chan string, 100),
-		processMgr:     pm,
-		logger:         logger,
-		config:         config,
-		sessionNGL:     config.DefaultNGL,
-		sessionCtxSize: config.DefaultCtxSize,
-		nglInput:       nglInput,
-		ctxSizeInput:   ctxInput,
-		hfSearchInput:  hfSearch,
-		hfClient:       hfmodels.NewClient(""),
+	localFilter := textinput.New()
+	localFilter.Placeholder = "name, arch, quant or #tag"
+	localFilter.CharLimit = 100
+	localFilter.Width = 30
+
+	tagsInput := textinput.New()
+	tagsInput.Placeholder = "coding, vision"
+	tagsInput.CharLimit = 200
+	tagsInput.Width = 50
+
+	noteInput := textinput.New()
+	noteInput.Placeholder = "Short note"
+	noteInput.CharLimit = 500
+	noteInput.Width = 50
+
+	authorInput := textinput.New()
+	authorInput.Placeholder = "any, e.g. unsloth"
+	authorInput.CharLimit = 100
+	authorInput.Width = 40
+
+	licenseInput := textinput.New()
+	licenseInput.Placeholder = "any, e.g. apache-2.0"
+	licenseInput.CharLimit = 100
+	licenseInput.Width = 40
+
+	userData, userDataErr := app.LoadUserData(config.UserDataFile)
+	if userDataErr != nil {
+		logger.Warn("Ignoring unreadable user data", zap.String("file", config.UserDataFile), zap.Error(userDataErr))
+	}
+
+	token, tokenSource := hf.Token(config.HFToken)
+	if token != "" {
+		logger.Debug("Using HuggingFace token", zap.String("source", tokenSource))
+	}
+	endpoint, endpointSource := hf.Endpoint(config.HFEndpoint)
+	if endpointSource != "" {
+		logger.Info("Using HuggingFace endpoint", zap.String("endpoint", endpoint), zap.String("source", endpointSource))
+	}
+	hfClient := hf.NewAPI(endpoint, token)
+	downloader := download.New(hfClient)
+	downloader.Endpoint = endpoint
+	downloader.Token = token
+	m := &Model{
+		allModels:        localModels,
+		models:           localModels,
+		selected:         0,
+		output:           "Ready. Select a model and press Enter for server, c for cli, e for config.\nPress 1/2/3 to switch tabs. In HF tab, press / to search, o for search options.\nPress f to star the selected model, t to tag it or add a note.\nPress p to list running processes, x to stop the focused one.",
+		outputChan:       make(chan OutputMsg, 100),
+		exitChan:         exitChan,
+		processMgr:       pm,
+		logger:           logger,
+		config:           config,
+		sessionNGL:       config.DefaultNGL,
+		sessionCtxSize:   config.DefaultCtxSize,
+		nglInput:         nglInput,
+		ctxSizeInput:     ctxInput,
+		hfSearchInput:    hfSearch,
+		localFilterInput: localFilter,
+		userData:         userData,
+		tagsInput:        tagsInput,
+		noteInput:        noteInput,
+		authorInput:      authorInput,
+		licenseInput:     licenseInput,
+		cardView:         viewport.New(0, 0),
+		hfCache:          hf.NewCache(hfClient, hf.CacheDir(config.HFCacheDir, endpoint), config.HFCacheTTL),
+		hfToken:          token,
+		hfEndpoint:       endpoint,
+		downloadStates:   make(map[string]download.State),
+		instanceOutput:   make(map[string]string),
+	}
+	m.applyView()
+
+	queue, err := download.OpenQueue(downloader, config.DownloadTarget(), config.DownloadStateFile, config.DownloadParallel)
+	if err != nil {
+		logger.Warn("Starting with an empty download queue", zap.String("file", config.DownloadStateFile), zap.Error(err))
+	}
+	m.downloads = queue
+	m.downloadItems = queue.Items()
+	for _, it := range m.downloadItems {
+		m.downloadStates[it.ID()] = it.State
 	}
+	queue.Start()
+
+	// Without local models the HuggingFace tab is the only useful one
+	if len(localModels) == 0 {
+		m.activeTab = 1
+		m.hfSearchFocused = true
+		m.hfSearchInput.Focus()
+		m.output = "No local models found. Type to search HuggingFace and press Enter.\n" +
+			"Press Esc to leave the search box, then 1 for the Local tab or p to list running processes."
+	}
+	if userData.ReadOnly() {
+		m.showAlert("User data not loaded", fmt.Sprintf(
+			"%s could not be read: %v\n\nFavorites, tags and notes cannot be changed until it is fixed, so the file is not overwritten.",
+			userData.Path(), userDataErr))
+	}
+	return m
 }
 
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
-	return tea.Sequence(
-		func() tea.Msg {
-			return InitMsg{}
-		},
-		m.checkOutputCmd(),
+	return tea.Batch(
+		tea.Sequence(
+			func() tea.Msg {
+				return InitMsg{}
+			},
+			m.checkOutputCmd(),
+		),
+		m.waitForExitCmd(),
+		m.waitForModelsCmd(),
+		m.waitForDownloadsCmd(),
 	)
 }
 
+// waitForModelsCmd blocks until the model watcher delivers a new list
+func (m *Model) waitForModelsCmd() tea.Cmd {
+	if m.modelUpdates == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return ModelsUpdatedMsg{Models: <-m.modelUpdates}
+	}
+}
+
+// renderLocalList renders the filter line and the visible part of the
+// Local list, scrolled so the selected model is in view
+func (m *Model) renderLocalList(width, height int, modelStyle, selectedStyle lipgloss.Style) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+
+	var b strings.Builder
+	if m.localFilterFocused {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Render("> "))
+		b.WriteString(m.localFilterInput.View())
+	} else {
+		b.WriteString(dimStyle.Render("/ to filter: "))
+		if filter := m.localFilterInput.Value(); filter != "" {
+			b.WriteString(filter)
+		} else {
+			b.WriteString("...")
+		}
+	}
+	b.WriteString("\n")
+
+	// Filter and position lines, and the selected model's summary line
+	rows := max(1, height-3)
+	m.listPageSize = rows
+	if m.selected < m.listOffset {
+		m.listOffset = m.selected
+	}
+	if m.selected >= m.listOffset+rows {
+		m.listOffset = m.selected - rows + 1
+	}
+	m.listOffset = max(0, min(m.listOffset, len(m.models)-rows))
+	end := min(len(m.models), m.listOffset+rows)
+
+	position := fmt.Sprintf("sort: %s (s)", m.sortMode)
+	if len(m.models) > 0 {
+		position += fmt.Sprintf(" · %d-%d of %d", m.listOffset+1, end, len(m.models))
+	}
+	if len(m.models) != len(m.allModels) {
+		position += fmt.Sprintf(" (%d total)", len(m.allModels))
+	}
+	b.WriteString(dimStyle.Render(position) + "\n")
+
+	if len(m.models) == 0 {
+		b.WriteString(dimStyle.Render("No models match. Esc clears the filter.") + "\n")
+		return b.String()
+	}
+
+	labelWidth := width - 8 // padding, border and the selection marker
+	for i := m.listOffset; i < end; i++ {
+		model := m.models[i]
+		annotation := m.userData.Model(model.Path)
+		label := favoriteMark(annotation, modelLabel(model))
+		if i == m.selected {
+			b.WriteString(selectedStyle.Render(" > " + truncate(label, labelWidth)))
+			summary := modelSummary(model)
+			if len(annotation.Tags) > 0 {
+				summary = strings.TrimPrefix(summary+" · "+formatTags(annotation.Tags), " · ")
+			}
+			if summary != "" {
+				b.WriteString("\n" + dimStyle.Render("     "+truncate(summary, labelWidth-2)))
+			}
+		} else {
+			b.WriteString(modelStyle.Render("   " + truncate(label, labelWidth)))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// truncate shortens s to at most n runes, ending in "..." if cut
+func truncate(s string, n int) string {
+	if n <= 3 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-3]) + "..."
+}
+
+// renderOnboarding explains where models come from when there are none
+func (m *Model) renderOnboarding() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C")).Bold(true)
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("No local models yet") + "\n\n")
+	b.WriteString(hintStyle.Render("Put .gguf files in:") + "\n")
+	for _, dir := range m.config.ModelDirs() {
+		b.WriteString("  " + dir + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("or press 2 and / to search HuggingFace;") + "\n")
+	b.WriteString(hintStyle.Render("models started with -hf are cached and") + "\n")
+	b.WriteString(hintStyle.Render("listed here.") + "\n")
+	if m.modelUpdates != nil {
+		b.WriteString("\n" + hintStyle.Render("This list updates as files appear.") + "\n")
+	}
+	return b.String()
+}
+
+// setModels replaces the local model list, keeping the selected model
+// selected if it is still there
+func (m *Model) setModels(list []models.Model) {
+	m.allModels = list
+	m.applyView()
+}
+
+// applyView rebuilds the shown list from the filter and sort mode. The
+// selection follows the selected model, or goes to the top if the model
+// is no longer shown.
+func (m *Model) applyView() {
+	var path string
+	if m.selected < len(m.models) {
+		path = m.models[m.selected].Path
+	}
+
+	text, tags := app.SplitTagQuery(m.localFilterInput.Value())
+	list := slices.Clone(m.allModels)
+	models.SortModels(list, m.sortMode)
+	m.models = m.filterLocal(models.FilterModels(list, text), tags)
+
+	m.selected = 0
+	for i, model := range m.models {
+		if model.Path == path {
+			m.selected = i
+			break
+		}
+	}
+}
+
+// moveSelection moves the Local selection by delta rows, stopping at the
+// ends of the list
+func (m *Model) moveSelection(delta int) {
+	if len(m.models) == 0 {
+		return
+	}
+	m.selected = max(0, min(len(m.models)-1, m.selected+delta))
+}
+
+// pageSize is the number of model rows that fit in the Local pane
+func (m *Model) pageSize() int {
+	if m.listPageSize > 0 {
+		return m.listPageSize
+	}
+	return 10
+}
+
+// waitForExitCmd blocks until the next instance exits on its own
+func (m *Model) waitForExitCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ProcessExitedMsg{Status: <-m.exitChan}
+	}
+}
+
 // checkOutputCmd creates a command to periodically check for output
 func (m *Model) checkOutputCmd() tea.Cmd {
 	return tea.Tick(100*time.Millisecond, func(t time.Time) tea.Msg {
@@ -168,6 +536,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		// Handle modals first
+		if m.showAlertModal {
+			return m.updateAlertModal(msg)
+		}
 		if m.showModal {
 			return m.updateModal(msg)
 		}
@@ -180,60 +551,115 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.showNoQuantModal {
 			return m.updateNoQuantModal(msg)
 		}
+		if m.showProcModal {
+			return m.updateProcModal(msg)
+		}
+		if m.showAnnotateModal {
+			return m.updateAnnotateModal(msg)
+		}
+		if m.showSearchOptions {
+			return m.updateSearchOptions(msg)
+		}
 
-		// Handle HF search input mode
+		// Handle search and filter input mode
 		if m.hfSearchFocused {
 			return m.updateHFSearch(msg)
 		}
+		if m.localFilterFocused {
+			return m.updateLocalFilter(msg)
+		}
 
 		// Handle CLI input mode
-		if m.cliMode && m.focusRight && m.processMgr.IsRunning() {
+		if m.cliMode && m.focusRight && m.processMgr.IsRunning(m.focusedInstance) {
+			if m.processMgr.IsPTY(m.focusedInstance) {
+				return m.updatePTYInput(msg)
+			}
 			return m.updateCliInput(msg)
 		}
 
+		if m.activeTab == 2 && !m.focusRight {
+			if cmd, ok := m.updateDownloadsTab(msg); ok {
+				return m, cmd
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.quit = true
-			m.processMgr.Stop()
+			m.processMgr.StopAll()
 			return m, tea.Quit
 		case "1":
 			m.activeTab = 0
 		case "2":
 			m.activeTab = 1
+		case "3":
+			m.activeTab = 2
 		case "/":
+			if m.activeTab == 0 && !m.focusRight {
+				m.localFilterFocused = true
+				m.localFilterInput.Focus()
+				return m, nil
+			}
 			if m.activeTab == 1 && !m.focusRight {
 				m.hfSearchFocused = true
 				m.hfSearchInput.Focus()
 				return m, nil
 			}
+		case "f":
+			if !m.focusRight {
+				m.toggleFavorite()
+			}
+		case "t":
+			if !m.focusRight {
+				m.openAnnotateModal()
+			}
+		case "o":
+			if m.activeTab == 1 && !m.focusRight {
+				m.openSearchOptions()
+			}
+		case "s":
+			if m.activeTab == 0 && !m.focusRight {
+				m.sortMode = m.sortMode.Next()
+				m.applyView()
+			}
+		case "pgup", "pgdown", "home", "end":
+			if m.activeTab == 0 && !m.focusRight {
+				switch msg.String() {
+				case "pgup":
+					m.moveSelection(-m.pageSize())
+				case "pgdown":
+					m.moveSelection(m.pageSize())
+				case "home":
+					m.moveSelection(-len(m.models))
+				case "end":
+					m.moveSelection(len(m.models))
+				}
+			}
 		case "up":
 			if m.focusRight {
 				if m.scrollOffset > 0 {
 					m.scrollOffset--
 				}
-			} else if m.activeTab == 0 {
+			} else if m.activeTab == 0 && len(m.models) > 0 {
 				m.selected--
 				if m.selected < 0 {
 					m.selected = len(m.models) - 1
 				}
-			} else if m.activeTab == 1 && len(m.hfModels) > 0 {
-				m.hfSelected--
-				if m.hfSelected < 0 {
-					m.hfSelected = len(m.hfModels) - 1
-				}
+			} else if m.activeTab == 1 {
+				return m, m.moveHFSelection(-1)
 			}
 		case "down":
 			if m.focusRight {
 				m.scrollOffset++
-			} else if m.activeTab == 0 {
+			} else if m.activeTab == 0 && len(m.models) > 0 {
 				m.selected = (m.selected + 1) % len(m.models)
-			} else if m.activeTab == 1 && len(m.hfModels) > 0 {
-				m.hfSelected = (m.hfSelected + 1) % len(m.hfModels)
+			} else if m.activeTab == 1 {
+				return m, m.moveHFSelection(1)
 			}
 		case "enter":
-			if m.activeTab == 0 {
+			if m.activeTab == 0 && len(m.models) > 0 {
 				m.output += "Enter key pressed - starting server\n"
-				m.startServer()
+				m.launchLocal(process.ModeServer)
 			} else if m.activeTab == 1 && len(m.hfModels) > 0 {
 				m.selectedHFModel = &m.hfModels[m.hfSelected]
 				m.loadingQuants = true
@@ -243,7 +669,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "c":
 			if m.activeTab == 0 {
-				m.startCli()
+				m.launchLocal(process.ModeCLI)
 			} else if m.activeTab == 1 && len(m.hfModels) > 0 {
 				m.selectedHFModel = &m.hfModels[m.hfSelected]
 				m.loadingQuants = true
@@ -252,45 +678,84 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.fetchQuants(m.selectedHFModel.ID)
 			}
 		case "i":
-			if m.activeTab == 1 && len(m.hfModels) > 0 {
+			if m.activeTab == 0 && len(m.models) > 0 {
+				m.localDetails = &m.models[m.selected]
+				m.showInfoModal = true
+			} else if m.activeTab == 1 && len(m.hfModels) > 0 {
 				model := m.hfModels[m.hfSelected]
 				m.loadingDetails = true
 				m.output += fmt.Sprintf("Fetching details for %s...\n", model.ID)
-				return m, m.fetchModelDetails(model.ID)
+				return m, tea.Batch(m.fetchModelDetails(model.ID), m.fetchReadme(model.ID))
 			}
 		case "e":
 			m.showModal = true
 			m.modalFocusIdx = 0
 			m.nglInput.Focus()
 			m.ctxSizeInput.Blur()
+		case "p":
+			m.showProcModal = true
+			m.procSelected = 0
+		case "x":
+			if m.focusedInstance != "" {
+				return m, m.stopInstance(m.focusedInstance)
+			}
 		case "tab":
 			m.focusRight = !m.focusRight
 		case "ctrl+l":
-			m.output = ""
+			if m.focusedInstance == "" {
+				m.output = ""
+			} else {
+				m.instanceOutput[m.focusedInstance] = ""
+			}
 			m.scrollOffset = 0
 		default:
-			if m.focusRight && m.processMgr.IsRunning() {
+			if m.focusRight && m.processMgr.IsRunning(m.focusedInstance) {
 				m.logger.Debug("Key pressed", zap.String("key", msg.String()))
 			}
 		}
-	case HFSearchResultMsg:
-		m.hfSearching = false
-		if msg.Err != nil {
-			m.output += fmt.Sprintf("HF search error: %v\n", msg.Err)
-		} else {
-			m.hfModels = msg.Models
-			m.hfSelected = 0
-			m.output += fmt.Sprintf("Found %d models\n", len(msg.Models))
+	case ProcessExitedMsg:
+		name := msg.Status.Instance
+		if _, ok := m.instanceOutput[name]; ok {
+			m.instanceOutput[name] += fmt.Sprintf("\n[%s]\n", msg.Status)
+		}
+		m.output += fmt.Sprintf("[%s] %s\n", name, msg.Status)
+		if m.focusedInstance == name {
+			m.cliMode = false
+			m.cliInputBuffer = ""
+			m.scrollOffset = len(strings.Split(m.currentOutput(), "\n"))
+		}
+		return m, m.waitForExitCmd()
+	case ModelsUpdatedMsg:
+		m.setModels(msg.Models)
+		if m.logger != nil {
+			m.logger.Debug("Local models updated", zap.Int("count", len(msg.Models)))
+		}
+		return m, m.waitForModelsCmd()
+	case DownloadsUpdatedMsg:
+		return m, tea.Batch(m.setDownloads(msg.Items), m.waitForDownloadsCmd())
+	case ProcessStoppedMsg:
+		if msg.Reason != "" {
+			m.output += fmt.Sprintf("[%s] %s\n", msg.Instance, msg.Reason)
 		}
+		delete(m.instanceOutput, msg.Instance)
+		if m.focusedInstance == msg.Instance {
+			m.focusInstance("")
+		}
+	case HFSearchResultMsg:
+		m.setHFResults(msg)
 	case HFQuantsResultMsg:
 		m.loadingQuants = false
 		if msg.Err != nil {
 			m.output += fmt.Sprintf("Error fetching quants: %v\n", msg.Err)
+			m.alertAccess(msg.Err, msg.ModelID)
 		} else if len(msg.Quants) == 0 {
 			m.showNoQuantModal = true
 			m.output += "No quantizations found for this model\n"
 		} else {
 			m.availableQuants = msg.Quants
+			m.quantsInfo = msg.Info
+			m.reportStale(msg.Info)
+			m.markedQuants = make(map[string]bool)
 			m.quantSelected = 0
 			m.showQuantModal = true
 			m.output += fmt.Sprintf("Found %d quantizations\n", len(msg.Quants))
@@ -299,27 +764,47 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.loadingDetails = false
 		if msg.Err != nil {
 			m.output += fmt.Sprintf("Error fetching details: %v\n", msg.Err)
+			m.alertAccess(msg.Err, msg.ModelID)
+			m.cardID = ""
 		} else {
 			m.modelDetails = msg.Details
+			m.detailsInfo = msg.Info
+			m.reportStale(msg.Info)
 			m.showInfoModal = true
 		}
+	case HFReadmeMsg:
+		m.setReadme(msg)
 	case tea.WindowSizeMsg:
 		m.windowWidth = msg.Width
 		m.windowHeight = msg.Height
 		m.scrollOffset = 0
+		m.resizePTYs()
 		return m, nil
 	case InitMsg:
 		m.output += "Init completed - starting output monitoring\n"
 		return m, nil
 	case CheckOutputMsg:
-		select {
-		case output := <-m.outputChan:
-			m.output += output
-			m.scrollOffset = len(strings.Split(m.output, "\n"))
-			return m, m.checkOutputCmd()
-		default:
-			return m, m.checkOutputCmd()
+		// Take what the instances wrote since the last tick, at most a
+		// channel's worth so chatty instances that keep refilling it
+		// cannot hold up the UI; the rest waits for the next tick
+		focused := false
+	drain:
+		for range cap(m.outputChan) {
+			select {
+			case out := <-m.outputChan:
+				if _, ok := m.instanceOutput[out.Instance]; ok {
+					// PTYs translate \n to \r\n
+					m.instanceOutput[out.Instance] += strings.ReplaceAll(out.Output, "\r\n", "\n")
+				}
+				focused = focused || out.Instance == m.focusedInstance
+			default:
+				break drain
+			}
+		}
+		if focused {
+			m.scrollOffset = len(strings.Split(m.currentOutput(), "\n"))
 		}
+		return m, m.checkOutputCmd()
 	}
 	return m, cmd
 }
@@ -342,6 +827,7 @@ func (m *Model) updateModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if ctx, err := strconv.Atoi(m.ctxSizeInput.Value()); err == nil && ctx >= 0 {
 			m.sessionCtxSize = ctx
 		}
+		m.sessionOverridden = true
 		m.showModal = false
 		m.nglInput.Blur()
 		m.ctxSizeInput.Blur()
@@ -383,17 +869,17 @@ func (m *Model) updateCliInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c":
 		m.quit = true
-		m.processMgr.Stop()
+		m.processMgr.StopAll()
 		return m, tea.Quit
 	case "esc":
 		m.cliMode = false
 		m.cliInputBuffer = ""
-		m.output += "\n[Exited CLI input mode]\n"
+		m.instanceOutput[m.focusedInstance] += "\n[Exited CLI input mode]\n"
 		return m, nil
 	case "enter":
 		input := m.cliInputBuffer + "\n"
-		if err := m.processMgr.WriteToStdin([]byte(input)); err != nil {
-			m.output += fmt.Sprintf("\n[Error sending input: %v]\n", err)
+		if err := m.processMgr.WriteToStdin(m.focusedInstance, []byte(input)); err != nil {
+			m.instanceOutput[m.focusedInstance] += fmt.Sprintf("\n[Error sending input: %v]\n", err)
 		}
 		m.cliInputBuffer = ""
 		return m, nil
@@ -413,11 +899,99 @@ func (m *Model) updateCliInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// updatePTYInput forwards keystrokes to a PTY-backed CLI instance as the
+// byte sequences a terminal would send. Esc leaves input mode.
+func (m *Model) updatePTYInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyEsc {
+		m.cliMode = false
+		m.instanceOutput[m.focusedInstance] += "\n[Exited CLI input mode]\n"
+		return m, nil
+	}
+
+	if data := keyBytes(msg); len(data) > 0 {
+		if err := m.processMgr.WriteToStdin(m.focusedInstance, data); err != nil {
+			m.instanceOutput[m.focusedInstance] += fmt.Sprintf("\n[Error sending input: %v]\n", err)
+		}
+	}
+	return m, nil
+}
+
+// keyBytes converts a key press into terminal input bytes
+func keyBytes(msg tea.KeyMsg) []byte {
+	var seq string
+	switch msg.Type {
+	case tea.KeyRunes:
+		seq = string(msg.Runes)
+	case tea.KeySpace:
+		seq = " "
+	case tea.KeyUp:
+		seq = "\x1b[A"
+	case tea.KeyDown:
+		seq = "\x1b[B"
+	case tea.KeyRight:
+		seq = "\x1b[C"
+	case tea.KeyLeft:
+		seq = "\x1b[D"
+	case tea.KeyHome:
+		seq = "\x1b[H"
+	case tea.KeyEnd:
+		seq = "\x1b[F"
+	case tea.KeyPgUp:
+		seq = "\x1b[5~"
+	case tea.KeyPgDown:
+		seq = "\x1b[6~"
+	case tea.KeyDelete:
+		seq = "\x1b[3~"
+	default:
+		// Enter, Tab, Backspace and Ctrl+<key> map to their control codes
+		if msg.Type >= 0 && msg.Type < 128 {
+			seq = string(rune(msg.Type))
+		}
+	}
+	if seq != "" && msg.Alt {
+		seq = "\x1b" + seq
+	}
+	return []byte(seq)
+}
+
+// resizePTYs passes the output pane size on to PTY-backed instances
+func (m *Model) resizePTYs() {
+	cols, rows := outputPaneSize(m.windowWidth, m.windowHeight)
+	for _, inst := range m.processMgr.List() {
+		if inst.PTY && inst.Exit == nil {
+			if err := m.processMgr.Resize(inst.Name, cols, rows); err != nil {
+				m.logger.Debug("Failed to resize pty", zap.String("instance", inst.Name), zap.Error(err))
+			}
+		}
+	}
+}
+
+// outputPaneSize returns the number of text columns and rows inside the
+// output pane, mirroring the layout in View
+func outputPaneSize(width, height int) (int, int) {
+	leftPaneWidth := width / 3
+	rightPaneWidth := width - leftPaneWidth - 2
+	paneHeight := height - 7
+	if paneHeight < 5 {
+		paneHeight = 5
+	}
+	// pane padding (2) and output text padding (2)
+	cols := rightPaneWidth - 4
+	if cols < 20 {
+		cols = 20
+	}
+	return cols, paneHeight - 4
+}
+
 // updateHFSearch handles input when HF search is focused
 func (m *Model) updateHFSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg.String() {
+	case "ctrl+c":
+		m.quit = true
+		m.processMgr.StopAll()
+		return m, tea.Quit
 	case "esc":
 		m.hfSearchFocused = false
 		m.hfSearchInput.Blur()
@@ -425,11 +999,26 @@ func (m *Model) updateHFSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		m.hfSearchFocused = false
 		m.hfSearchInput.Blur()
-		query := m.hfSearchInput.Value()
-		if query != "" {
-			m.hfSearching = true
-			m.output += fmt.Sprintf("Searching HuggingFace for '%s'...\n", query)
-			return m, m.searchHFModels(query)
+		query, tags := app.SplitTagQuery(m.hfSearchInput.Value())
+		m.hfTags = tags
+		if query == "" && len(tags) > 0 {
+			// Only tags: list the tagged repos without asking the Hub
+			m.hfResults = nil
+			m.hfInfo = hf.Info{}
+			m.hfOptions = hf.SearchOptions{}
+			m.hfSearching = false
+			m.hfNext = ""
+			for _, id := range m.userData.ReposWithTags(tags) {
+				m.hfResults = append(m.hfResults, hfmodels.Model{ID: id})
+			}
+			m.hfSelected = 0
+			m.applyHFView()
+			m.output += fmt.Sprintf("%d repos tagged %s\n", len(m.hfModels), formatTags(tags))
+			return m, nil
+		}
+		if query != "" || m.userData.HFSearch.Author != "" {
+			m.output += fmt.Sprintf("Searching HuggingFace for '%s' (%s)...\n", query, filtersSummary(m.userData.HFSearch))
+			return m, m.startHFSearch(query)
 		}
 		return m, nil
 	}
@@ -438,36 +1027,93 @@ func (m *Model) updateHFSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// searchHFModels performs async search on HuggingFace
-func (m *Model) searchHFModels(query string) tea.Cmd {
-	return func() tea.Msg {
-		models, err := m.hfClient.ListModels(hfmodels.ListModelsOptions{
-			Search:      query,
-			LibraryName: "gguf",
-			Limit:       20,
-			Sort:        "downloads",
-			Direction:   -1,
-		})
-		return HFSearchResultMsg{Models: models, Err: err}
+// updateLocalFilter handles keys while the Local filter is focused. The
+// list is filtered as you type; Enter keeps the filter, Esc clears it.
+func (m *Model) updateLocalFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "ctrl+c":
+		m.quit = true
+		m.processMgr.StopAll()
+		return m, tea.Quit
+	case "esc":
+		m.localFilterFocused = false
+		m.localFilterInput.Blur()
+		m.localFilterInput.SetValue("")
+		m.applyView()
+		return m, nil
+	case "enter":
+		m.localFilterFocused = false
+		m.localFilterInput.Blur()
+		return m, nil
+	case "up":
+		m.moveSelection(-1)
+		return m, nil
+	case "down":
+		m.moveSelection(1)
+		return m, nil
 	}
+
+	m.localFilterInput, cmd = m.localFilterInput.Update(msg)
+	m.applyView()
+	return m, cmd
 }
 
 // fetchQuants fetches available quantizations for a model
 func (m *Model) fetchQuants(modelID string) tea.Cmd {
 	return func() tea.Msg {
-		quants, err := m.hfClient.GetAvailableQuants(modelID)
-		return HFQuantsResultMsg{ModelID: modelID, Quants: quants, Err: err}
+		quants, info, err := m.hfCache.GetAvailableQuants(modelID)
+		return HFQuantsResultMsg{ModelID: modelID, Quants: quants, Info: info, Err: err}
 	}
 }
 
 // fetchModelDetails fetches detailed information about a model
 func (m *Model) fetchModelDetails(modelID string) tea.Cmd {
 	return func() tea.Msg {
-		details, err := m.hfClient.GetModelDetails(modelID)
-		return HFModelDetailsMsg{Details: details, Err: err}
+		details, info, err := m.hfCache.GetModelDetails(modelID)
+		return HFModelDetailsMsg{ModelID: modelID, Details: details, Info: info, Err: err}
+	}
+}
+
+// alertAccess shows an alert if err means repo needs a token or is gated
+func (m *Model) alertAccess(err error, repo string) {
+	if hint := hf.AccessHint(err, m.hfEndpoint, repo, m.hfToken != ""); hint != "" {
+		m.showAlert("Access denied", hint)
+	}
+}
+
+// reportStale notes in the log that the Hub was unreachable and a cached
+// response is shown
+func (m *Model) reportStale(info hf.Info) {
+	if info.Stale {
+		m.output += fmt.Sprintf("HuggingFace unreachable (%v), using data from %s\n", info.Err, staleAge(info))
 	}
 }
 
+// staleNote is the marker shown next to data the Hub could not refresh,
+// "" for current data
+func staleNote(info hf.Info) string {
+	if !info.Stale {
+		return ""
+	}
+	return "offline · cached " + staleAge(info)
+}
+
+// staleAge says how old a cached response is, e.g. "3h ago"
+func staleAge(info hf.Info) string {
+	age := time.Since(info.FetchedAt)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+}
+
 // updateQuantModal handles input when quantization modal is visible
 func (m *Model) updateQuantModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if len(m.availableQuants) == 0 {
@@ -494,7 +1140,7 @@ func (m *Model) updateQuantModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.selectedHFModel != nil && m.quantSelected < len(m.availableQuants) {
 			quant := m.availableQuants[m.quantSelected]
 			m.showQuantModal = false
-			m.startHFServer(m.selectedHFModel.ID, quant)
+			m.launch(m.hfSpec(process.ModeServer, m.selectedHFModel.ID, quant))
 			m.selectedHFModel = nil
 			m.availableQuants = nil
 		}
@@ -503,7 +1149,29 @@ func (m *Model) updateQuantModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.selectedHFModel != nil && m.quantSelected < len(m.availableQuants) {
 			quant := m.availableQuants[m.quantSelected]
 			m.showQuantModal = false
-			m.startHFCli(m.selectedHFModel.ID, quant)
+			m.launch(m.hfSpec(process.ModeCLI, m.selectedHFModel.ID, quant))
+			m.selectedHFModel = nil
+			m.availableQuants = nil
+		}
+		return m, nil
+	case " ":
+		quant := m.availableQuants[m.quantSelected]
+		m.markedQuants[quant] = !m.markedQuants[quant]
+		return m, nil
+	case "d":
+		if m.selectedHFModel != nil && m.quantSelected < len(m.availableQuants) {
+			// The marked quants, or the highlighted one if none is marked
+			var quants []string
+			for _, quant := range m.availableQuants {
+				if m.markedQuants[quant] {
+					quants = append(quants, quant)
+				}
+			}
+			if len(quants) == 0 {
+				quants = []string{m.availableQuants[m.quantSelected]}
+			}
+			m.showQuantModal = false
+			m.queueDownloads(m.selectedHFModel.ID, quants)
 			m.selectedHFModel = nil
 			m.availableQuants = nil
 		}
@@ -512,14 +1180,28 @@ func (m *Model) updateQuantModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// updateInfoModal handles input when info modal is visible
+// updateInfoModal handles input when info modal is visible; other keys
+// scroll the model card of HF repos
 func (m *Model) updateInfoModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc", "i", "q":
 		m.showInfoModal = false
 		m.modelDetails = nil
+		m.localDetails = nil
+		m.cardID = ""
+		return m, nil
+	case "home":
+		m.cardView.GotoTop()
+		return m, nil
+	case "end":
+		m.cardView.GotoBottom()
 		return m, nil
 	}
+	if m.modelDetails != nil {
+		var cmd tea.Cmd
+		m.cardView, cmd = m.cardView.Update(msg)
+		return m, cmd
+	}
 	return m, nil
 }
 
@@ -533,14 +1215,14 @@ func (m *Model) updateNoQuantModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter", "y":
 		if m.selectedHFModel != nil {
 			m.showNoQuantModal = false
-			m.startHFServer(m.selectedHFModel.ID, "")
+			m.launch(m.hfSpec(process.ModeServer, m.selectedHFModel.ID, ""))
 			m.selectedHFModel = nil
 		}
 		return m, nil
 	case "c":
 		if m.selectedHFModel != nil {
 			m.showNoQuantModal = false
-			m.startHFCli(m.selectedHFModel.ID, "")
+			m.launch(m.hfSpec(process.ModeCLI, m.selectedHFModel.ID, ""))
 			m.selectedHFModel = nil
 		}
 		return m, nil
@@ -548,6 +1230,49 @@ func (m *Model) updateNoQuantModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateProcModal handles input when the process list modal is visible.
+// Row 0 is the main log, the remaining rows are running instances.
+func (m *Model) updateProcModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	instances := m.processMgr.List()
+	rows := len(instances) + 1
+	if m.procSelected >= rows {
+		m.procSelected = rows - 1
+	}
+
+	switch msg.String() {
+	case "esc", "p", "q":
+		m.showProcModal = false
+		return m, nil
+	case "up":
+		m.procSelected--
+		if m.procSelected < 0 {
+			m.procSelected = rows - 1
+		}
+		return m, nil
+	case "down":
+		m.procSelected = (m.procSelected + 1) % rows
+		return m, nil
+	case "enter":
+		if m.procSelected == 0 {
+			m.focusInstance("")
+		} else {
+			m.focusInstance(instances[m.procSelected-1].Name)
+		}
+		m.showProcModal = false
+		return m, nil
+	case "x":
+		if m.procSelected > 0 {
+			cmd := m.stopInstance(instances[m.procSelected-1].Name)
+			if m.procSelected >= rows-1 {
+				m.procSelected = rows - 2
+			}
+			return m, cmd
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
 // View renders the UI
 func (m *Model) View() string {
 	// Use stored window dimensions or fall back to defaults
@@ -623,64 +1348,30 @@ func (m *Model) View() string {
 	// Render tabs
 	tab1 := inactiveTabStyle.Render(" 1:Local ")
 	tab2 := inactiveTabStyle.Render(" 2:HuggingFace ")
-	if m.activeTab == 0 {
+	tab3 := inactiveTabStyle.Render(" 3:Downloads ")
+	switch m.activeTab {
+	case 0:
 		tab1 = activeTabStyle.Render(" 1:Local ")
-	} else {
+	case 1:
 		tab2 = activeTabStyle.Render(" 2:HuggingFace ")
+	case 2:
+		tab3 = activeTabStyle.Render(" 3:Downloads ")
 	}
-	tabs := lipgloss.JoinHorizontal(lipgloss.Top, tab1, tab2)
+	tabs := lipgloss.JoinHorizontal(lipgloss.Top, tab1, tab2, tab3)
 
 	// Create left pane content based on active tab
 	var leftContent string
 	if m.activeTab == 0 {
 		// Local models tab
-		var modelList strings.Builder
-		for i, model := range m.models {
-			if i == m.selected {
-				modelList.WriteString(selectedModelStyle.Render(" > " + model))
-			} else {
-				modelList.WriteString(modelStyle.Render("   " + model))
-			}
-			modelList.WriteString("\n")
-		}
-		leftContent = modelList.String()
-	} else {
-		// HuggingFace tab
-		var hfContent strings.Builder
-
-		// Search input
-		if m.hfSearchFocused {
-			hfContent.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Render("> "))
-			hfContent.WriteString(m.hfSearchInput.View())
-		} else {
-			hfContent.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render("/ to search: "))
-			if m.hfSearchInput.Value() != "" {
-				hfContent.WriteString(m.hfSearchInput.Value())
-			} else {
-				hfContent.WriteString("...")
-			}
-		}
-		hfContent.WriteString("\n\n")
-
-		if m.hfSearching {
-			hfContent.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C")).Render("Searching..."))
-		} else if len(m.hfModels) == 0 {
-			hfContent.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render("No results. Press / to search."))
+		if len(m.allModels) == 0 {
+			leftContent = m.renderOnboarding()
 		} else {
-			for i, model := range m.hfModels {
-				displayName := model.ID
-				if len(displayName) > leftPaneWidth-8 {
-					displayName = displayName[:leftPaneWidth-11] + "..."
-				}
-				if i == m.hfSelected {
-					hfContent.WriteString(selectedModelStyle.Render(" > " + displayName))
-				} else {
-					hfContent.WriteString(modelStyle.Render("   " + displayName))
-				}
-				hfContent.WriteString("\n")
-			}
+			leftContent = m.renderLocalList(leftPaneWidth, outputHeight, modelStyle, selectedModelStyle)
 		}
-		leftContent = hfContent.String()
+	} else if m.activeTab == 2 {
+		leftContent = m.renderDownloads(leftPaneWidth, modelStyle, selectedModelStyle)
+	} else {
+		leftContent = m.renderHFList(leftPaneWidth, outputHeight, modelStyle, selectedModelStyle)
 	}
 
 	leftPane := lipgloss.NewStyle().
@@ -700,7 +1391,7 @@ func (m *Model) View() string {
 		)
 
 	// Create right pane (output) with scrolling
-	outputLines := strings.Split(m.output, "\n")
+	outputLines := strings.Split(m.currentOutput(), "\n")
 	totalLines := len(outputLines)
 
 	// Auto-scroll to bottom if scrollOffset would show past the end
@@ -724,6 +1415,11 @@ func (m *Model) View() string {
 
 	visibleOutput := strings.Join(outputLines[start:end], "\n")
 
+	outputTitle := " Shell Output "
+	if m.focusedInstance != "" {
+		outputTitle = fmt.Sprintf(" %s ", m.focusedInstance)
+	}
+
 	rightPane := lipgloss.NewStyle().
 		Width(rightPaneWidth).
 		Height(paneHeight).
@@ -734,7 +1430,7 @@ func (m *Model) View() string {
 		Render(
 			lipgloss.JoinVertical(
 				lipgloss.Left,
-				titleStyle.Render(" Shell Output "),
+				titleStyle.Render(outputTitle),
 				"",
 				outputStyle.Render(visibleOutput),
 			),
@@ -749,16 +1445,30 @@ func (m *Model) View() string {
 
 	// Add status bar
 	var statusText string
-	if m.cliMode && m.cliInputBuffer != "" {
+	if m.cliMode && m.processMgr.IsPTY(m.focusedInstance) {
+		statusText = " PTY input: keys go to llama-cli (Ctrl+C interrupts), Esc to leave input mode "
+	} else if m.cliMode && m.cliInputBuffer != "" {
 		statusText = fmt.Sprintf(" > %s_ ", m.cliInputBuffer)
 	} else if m.cliMode {
 		statusText = " > _ (CLI mode - type and press Enter, Esc to exit) "
 	} else if m.activeTab == 0 && len(m.models) > 0 {
-		statusText = fmt.Sprintf(" Selected: %s | NGL: %d | CtxSize: %d ", m.models[m.selected], m.sessionNGL, m.sessionCtxSize)
+		statusText = fmt.Sprintf(" Selected: %s | NGL: %d | CtxSize: %d ", m.models[m.selected].Name, m.sessionNGL, m.sessionCtxSize)
 	} else if m.activeTab == 1 && len(m.hfModels) > 0 {
 		statusText = fmt.Sprintf(" HF: %s | NGL: %d | CtxSize: %d ", m.hfModels[m.hfSelected].ID, m.sessionNGL, m.sessionCtxSize)
 	} else {
-		statusText = fmt.Sprintf(" NGL: %d | CtxSize: %d | Press 1/2 for tabs ", m.sessionNGL, m.sessionCtxSize)
+		statusText = fmt.Sprintf(" NGL: %d | CtxSize: %d | Press 1/2/3 for tabs ", m.sessionNGL, m.sessionCtxSize)
+	}
+	if instances := m.processMgr.List(); len(instances) > 0 && !m.cliMode {
+		running := 0
+		for _, inst := range instances {
+			if inst.Exit == nil {
+				running++
+			}
+		}
+		statusText += fmt.Sprintf("| Running: %d/%d (p to list) ", running, len(instances))
+	}
+	if m.activeTab != 2 && !m.cliMode {
+		statusText += m.downloadSummary()
 	}
 	status := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#FAFAFA")).
@@ -782,6 +1492,18 @@ func (m *Model) View() string {
 	if m.showNoQuantModal {
 		result = m.renderNoQuantModal(result, width, height)
 	}
+	if m.showProcModal {
+		result = m.renderProcModal(result, width, height)
+	}
+	if m.showAnnotateModal {
+		result = m.renderAnnotateModal(result, width, height)
+	}
+	if m.showSearchOptions {
+		result = m.renderSearchOptions(result, width, height)
+	}
+	if m.showAlertModal {
+		result = m.renderAlertModal(result, width, height)
+	}
 
 	return result
 }
@@ -854,6 +1576,11 @@ func (m *Model) renderQuantModal(base string, width, height int) string {
 
 	for i := startIdx; i < endIdx; i++ {
 		q := m.availableQuants[i]
+		if m.markedQuants[q] {
+			q = "[x] " + q
+		} else {
+			q = "[ ] " + q
+		}
 		if i == m.quantSelected {
 			quantList.WriteString(selectedStyle.Render("> " + q))
 		} else {
@@ -880,10 +1607,11 @@ func (m *Model) renderQuantModal(base string, width, height int) string {
 	modalContent := lipgloss.JoinVertical(lipgloss.Left,
 		lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9")).Bold(true).Render("Select Quantization"+scrollInfo),
 		lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render(modelName),
-		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C")).Render(staleNote(m.quantsInfo)),
 		quantList.String(),
 		"",
 		lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render("Enter: Server | c: CLI | Esc: Cancel"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render("Space: Mark | d: Download marked or selected"),
 	)
 
 	modal := lipgloss.NewStyle().
@@ -899,13 +1627,17 @@ func (m *Model) renderQuantModal(base string, width, height int) string {
 		lipgloss.WithWhitespaceForeground(lipgloss.Color("#000000")))
 }
 
-// renderInfoModal renders the model info modal
+// renderInfoModal renders the model info modal. For HF repos it fills most
+// of the screen to show the scrollable model card below the details.
 func (m *Model) renderInfoModal(base string, width, height int) string {
+	if m.localDetails != nil {
+		return m.renderLocalInfoModal(base, width, height)
+	}
 	if m.modelDetails == nil {
 		return base
 	}
 
-	modalWidth := 60
+	modalWidth := min(max(60, width-8), 120)
 	d := m.modelDetails
 
 	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2"))
@@ -914,7 +1646,11 @@ func (m *Model) renderInfoModal(base string, width, height int) string {
 
 	var info strings.Builder
 	info.WriteString(labelStyle.Render("Model: "))
-	info.WriteString(valueStyle.Render(d.ID) + "\n\n")
+	info.WriteString(valueStyle.Render(d.ID) + "\n")
+	if note := staleNote(m.detailsInfo); note != "" {
+		info.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C")).Render(note))
+	}
+	info.WriteString("\n")
 
 	info.WriteString(labelStyle.Render("Downloads: "))
 	info.WriteString(infoStyle.Render(fmt.Sprintf("%d", d.Downloads)) + "\n")
@@ -950,6 +1686,7 @@ func (m *Model) renderInfoModal(base string, width, height int) string {
 		info.WriteString(labelStyle.Render("License: "))
 		info.WriteString(infoStyle.Render(license) + "\n")
 	}
+	writeAnnotation(&info, m.userData.Repo(d.ID), labelStyle, infoStyle)
 
 	// Show available quants
 	quants := hfmodels.ExtractQuantsFromSiblings(d.Siblings)
@@ -971,11 +1708,23 @@ func (m *Model) renderInfoModal(base string, width, height int) string {
 		}
 	}
 
+	cardTitle := labelStyle.Render("Model Card")
+	if note := staleNote(m.cardInfo); note != "" {
+		cardTitle += "  " + lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C")).Render(note)
+	}
+	// Border, padding, title, card title, footer and a margin around
+	cardHeight := height - lipgloss.Height(info.String()) - 11
+	card := m.renderCard(modalWidth-4, cardHeight)
+
 	modalContent := lipgloss.JoinVertical(lipgloss.Left,
 		lipgloss.NewStyle().Foreground(lipgloss.Color("#FF79C6")).Bold(true).Render("Model Information"),
 		"",
 		info.String(),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render("Press Esc to close"),
+		cardTitle,
+		card,
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render(
+			fmt.Sprintf("↑/↓ PgUp/PgDn: Scroll card (%.0f%%) | Esc: Close", m.cardView.ScrollPercent()*100)),
 	)
 
 	modal := lipgloss.NewStyle().
@@ -991,6 +1740,152 @@ func (m *Model) renderInfoModal(base string, width, height int) string {
 		lipgloss.WithWhitespaceForeground(lipgloss.Color("#000000")))
 }
 
+// modelLabel is the list entry for a local model with its badges
+func modelLabel(model models.Model) string {
+	label := model.Name
+	if info := model.ShardInfo(); info != "" {
+		label += " [" + info + "]"
+	}
+	if model.Projector != nil {
+		label += " [vision]"
+	}
+	if model.Source != "" && model.Source != models.SourceLocal {
+		label += " [" + string(model.Source) + "]"
+	}
+	return label
+}
+
+// modelSummary returns a one-line summary of a local model's GGUF header
+func modelSummary(model models.Model) string {
+	g := model.GGUF
+	if g == nil {
+		return ""
+	}
+	var parts []string
+	for _, part := range []string{g.Architecture, g.Params(), g.Quantization()} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if g.ContextLength > 0 {
+		parts = append(parts, fmt.Sprintf("%dk ctx", g.ContextLength/1024))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// renderLocalInfoModal renders the GGUF metadata of a local model
+func (m *Model) renderLocalInfoModal(base string, width, height int) string {
+	modalWidth := 60
+	model := m.localDetails
+
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2"))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9")).Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+
+	var info strings.Builder
+	info.WriteString(labelStyle.Render("Model: "))
+	info.WriteString(valueStyle.Render(model.Name) + "\n\n")
+
+	info.WriteString(labelStyle.Render("Path: "))
+	info.WriteString(infoStyle.Render(model.Path) + "\n")
+	if model.Source != "" && model.Source != models.SourceLocal {
+		info.WriteString(labelStyle.Render("Source: "))
+		info.WriteString(infoStyle.Render(string(model.Source)) + "\n")
+	}
+	if model.HFRepo != "" {
+		info.WriteString(labelStyle.Render("HF Repo: "))
+		info.WriteString(infoStyle.Render(model.HFRepo) + "\n")
+	}
+	info.WriteString(labelStyle.Render("Size: "))
+	info.WriteString(infoStyle.Render(formatBytes(model.Size)) + "\n")
+	if shards := model.ShardInfo(); shards != "" {
+		info.WriteString(labelStyle.Render("Shards: "))
+		info.WriteString(infoStyle.Render(shards) + "\n")
+	}
+	if model.Projector != nil {
+		info.WriteString(labelStyle.Render("Projector: "))
+		info.WriteString(infoStyle.Render(model.Projector.Name) + "\n")
+	}
+	if model.SHA256 != "" {
+		info.WriteString(labelStyle.Render("SHA256: "))
+		info.WriteString(infoStyle.Render(model.SHA256[:16]+"…") + "\n")
+	}
+	if !model.LastUsed.IsZero() {
+		info.WriteString(labelStyle.Render("Last Used: "))
+		info.WriteString(infoStyle.Render(model.LastUsed.Format("2006-01-02 15:04")) + "\n")
+	}
+	if warning := model.MissingShardsWarning(); warning != "" {
+		info.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Render("Warning: "+warning) + "\n")
+	}
+	writeAnnotation(&info, m.userData.Model(model.Path), labelStyle, infoStyle)
+
+	if g := model.GGUF; g != nil {
+		field := func(label, value string) {
+			if value == "" || value == "0" {
+				return
+			}
+			info.WriteString(labelStyle.Render(label + ": "))
+			info.WriteString(infoStyle.Render(value) + "\n")
+		}
+
+		info.WriteString("\n")
+		field("Name", g.Name)
+		field("Architecture", g.Architecture)
+		field("Parameters", g.Params())
+		field("Quantization", g.Quantization())
+		field("Context Length", fmt.Sprintf("%d", g.ContextLength))
+		field("Layers", fmt.Sprintf("%d", g.BlockCount))
+		field("Tokenizer", g.TokenizerModel)
+		field("GGUF Version", fmt.Sprintf("%d", g.Version))
+
+		if g.ChatTemplate != "" {
+			info.WriteString("\n" + labelStyle.Render("Chat Template:") + "\n")
+			lines := strings.Split(strings.TrimSpace(g.ChatTemplate), "\n")
+			for i, line := range lines {
+				if i == 6 {
+					info.WriteString(dimStyle.Render(fmt.Sprintf("  ... %d more lines", len(lines)-i)) + "\n")
+					break
+				}
+				if len(line) > modalWidth-6 {
+					line = line[:modalWidth-9] + "..."
+				}
+				info.WriteString(dimStyle.Render("  "+line) + "\n")
+			}
+		}
+	} else {
+		info.WriteString("\n" + dimStyle.Render("No GGUF metadata available") + "\n")
+	}
+
+	modalContent := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#FF79C6")).Bold(true).Render("Model Information"),
+		"",
+		info.String(),
+		dimStyle.Render("Press Esc to close"),
+	)
+
+	modal := lipgloss.NewStyle().
+		Width(modalWidth).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#FF79C6")).
+		Background(lipgloss.Color("#282A36")).
+		Render(modalContent)
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("#000000")))
+}
+
+// formatBytes renders a file size in MB or GB
+func formatBytes(n int64) string {
+	sizeMB := float64(n) / (1024 * 1024)
+	if sizeMB >= 1024 {
+		return fmt.Sprintf("%.2f GB", sizeMB/1024)
+	}
+	return fmt.Sprintf("%.2f MB", sizeMB)
+}
+
 // renderNoQuantModal renders the no-quant confirmation modal
 func (m *Model) renderNoQuantModal(base string, width, height int) string {
 	modalWidth := 55
@@ -1027,108 +1922,245 @@ func (m *Model) renderNoQuantModal(base string, width, height int) string {
 		lipgloss.WithWhitespaceForeground(lipgloss.Color("#000000")))
 }
 
-// startServer starts the llama-server process
-func (m *Model) startServer() {
-	modelName := m.models[m.selected]
-	modelPath := filepath.Join(m.config.ModelsDir, modelName)
+// renderProcModal renders the running process list modal
+func (m *Model) renderProcModal(base string, width, height int) string {
+	modalWidth := 60
+	instances := m.processMgr.List()
 
-	m.output = fmt.Sprintf("Starting llama-server for %s (NGL=%d, CtxSize=%d)...\n", modelName, m.sessionNGL, m.sessionCtxSize)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Bold(true)
 
-	if err := m.processMgr.StartServer(modelPath, modelName, m.sessionNGL, m.sessionCtxSize); err != nil {
-		m.output += "Error starting server: " + err.Error() + "\n"
-		if m.logger != nil {
-			m.logger.Error("Failed to start server", zap.Error(err))
+	rows := []string{"Main log"}
+	for _, inst := range instances {
+		if inst.Exit != nil {
+			rows = append(rows, fmt.Sprintf("%s [%s, %s]", inst.Name, inst.Mode, inst.Exit))
+		} else {
+			rows = append(rows, fmt.Sprintf("%s [%s, pid %d]", inst.Name, inst.Mode, inst.PID))
+		}
+	}
+
+	var procList strings.Builder
+	for i, row := range rows {
+		if len(row) > modalWidth-6 {
+			row = row[:modalWidth-9] + "..."
+		}
+		marker := "  "
+		if (i == 0 && m.focusedInstance == "") || (i > 0 && instances[i-1].Name == m.focusedInstance) {
+			marker = "* "
+		}
+		if i == m.procSelected {
+			procList.WriteString(selectedStyle.Render("> " + marker + row))
+		} else {
+			procList.WriteString(labelStyle.Render("  " + marker + row))
+		}
+		if i < len(rows)-1 {
+			procList.WriteString("\n")
 		}
-		return
 	}
 
-	m.output += "Process started (checking for output...)\n"
-	go m.readOutput()
+	modalContent := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9")).Bold(true).Render(fmt.Sprintf("Processes (%d)", len(instances))),
+		"",
+		procList.String(),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4")).Render("Enter: Focus | x: Stop/Remove | Esc: Close"),
+	)
+
+	modal := lipgloss.NewStyle().
+		Width(modalWidth).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#BD93F9")).
+		Background(lipgloss.Color("#282A36")).
+		Render(modalContent)
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("#000000")))
 }
 
-// startCli starts the llama-cli process
-func (m *Model) startCli() {
-	modelName := m.models[m.selected]
-	modelPath := filepath.Join(m.config.ModelsDir, modelName)
+// localSpec describes a launch of the selected local model
+func (m *Model) localSpec(mode process.Mode) process.LaunchSpec {
+	model := m.models[m.selected]
+	spec := process.LaunchSpec{
+		Mode:      mode,
+		ModelPath: model.Path,
+		ModelName: model.Name,
+		NGL:       m.sessionNGL,
+		CtxSize:   m.sessionCtxSize,
+	}
+	if model.Projector != nil {
+		spec.Projector = model.Projector.Path
+	}
+	return spec
+}
 
-	m.output = fmt.Sprintf("Starting llama-cli for %s (NGL=%d, CtxSize=%d)...\n", modelName, m.sessionNGL, m.sessionCtxSize)
+// launchLocal starts the selected local model, warning about missing
+// shards of split models
+func (m *Model) launchLocal(mode process.Mode) {
+	if len(m.models) == 0 {
+		return
+	}
+	var warnings []string
+	model := m.models[m.selected]
+	if warning := model.MissingShardsWarning(); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	if !m.launch(m.localSpec(mode), warnings...) {
+		return
+	}
 
-	if err := m.processMgr.StartCLI(modelPath, modelName, m.sessionNGL, m.sessionCtxSize); err != nil {
-		m.output += "Error starting CLI: " + err.Error() + "\n"
-		if m.logger != nil {
-			m.logger.Error("Failed to start CLI", zap.Error(err))
+	now := time.Now()
+	for i := range m.allModels {
+		if m.allModels[i].Path == model.Path {
+			m.allModels[i].LastUsed = now
 		}
-		return
 	}
+	m.applyView()
+	// A background rescan may hold the index
+	go func() {
+		if err := models.MarkUsed(m.config.IndexFile, model.Path); err != nil && m.logger != nil {
+			m.logger.Warn("Failed to update model index", zap.Error(err))
+		}
+	}()
+}
 
-	m.focusRight = true // Switch focus to right pane for interactive CLI
-	m.cliMode = true    // Enable CLI input mode
-	m.output += "CLI process started - type your message and press Enter...\n"
-	go m.readOutput()
+// hfSpec describes a launch of a HuggingFace repo, quant may be empty
+func (m *Model) hfSpec(mode process.Mode, repo, quant string) process.LaunchSpec {
+	return process.LaunchSpec{
+		Mode:      mode,
+		ModelName: repo,
+		HFRepo:    repo,
+		HFQuant:   quant,
+		NGL:       m.sessionNGL,
+		CtxSize:   m.sessionCtxSize,
+		Env:       m.hfEnv(),
+	}
 }
 
-// startHFServer starts the llama-server with a HuggingFace model
-func (m *Model) startHFServer(hfModel, quant string) {
-	m.output = fmt.Sprintf("Starting llama-server for HF model %s:%s (NGL=%d, CtxSize=%d)...\n",
-		hfModel, quant, m.sessionNGL, m.sessionCtxSize)
+// hfEnv passes the HuggingFace token and endpoint on to llama.cpp for -hf
+// downloads
+func (m *Model) hfEnv() []string {
+	var env []string
+	if m.hfToken != "" {
+		env = append(env, "HF_TOKEN="+m.hfToken)
+	}
+	if m.hfEndpoint != hf.DefaultEndpoint {
+		env = append(env, "HF_ENDPOINT="+m.hfEndpoint)
+	}
+	return env
+}
 
-	if err := m.processMgr.StartServerHF(hfModel, quant, m.sessionNGL, m.sessionCtxSize); err != nil {
-		m.output += "Error starting server: " + err.Error() + "\n"
+// launch starts a llama-server or llama-cli instance with the matching
+// launch profile applied and focuses it. CLI instances also take over the
+// keyboard. Warnings are shown above the process output. It reports
+// whether the process started.
+func (m *Model) launch(spec process.LaunchSpec, warnings ...string) bool {
+	spec, profile := m.config.LaunchSpec(spec)
+	if m.sessionOverridden {
+		spec.NGL = m.sessionNGL
+		spec.CtxSize = m.sessionCtxSize
+	}
+
+	binary := "llama-server"
+	if spec.Mode == process.ModeCLI {
+		binary = "llama-cli"
+	}
+	source := ""
+	if spec.IsHF() {
+		source = "HF model "
+	}
+	header := fmt.Sprintf("Starting %s for %s%s (NGL=%d, CtxSize=%d)...\n",
+		binary, source, spec.DisplayName(), spec.NGL, spec.CtxSize)
+	if profile != nil {
+		header += fmt.Sprintf("Using profile %q\n", profile.Label())
+	}
+	for _, warning := range warnings {
+		header += "Warning: " + warning + "\n"
+	}
+
+	name, err := m.processMgr.Start(spec)
+	if err != nil {
+		m.output += header + "Error starting " + spec.Mode.String() + ": " + err.Error() + "\n"
+		m.focusInstance("")
 		if m.logger != nil {
-			m.logger.Error("Failed to start HF server", zap.Error(err))
+			m.logger.Error("Failed to start "+spec.Mode.String(), zap.String("model", spec.DisplayName()), zap.Error(err))
 		}
-		return
+		return false
 	}
 
-	m.output += "Process started (model will be downloaded if needed)...\n"
-	go m.readOutput()
+	status := "Process started (checking for output...)\n"
+	switch {
+	case spec.IsHF() && spec.Mode == process.ModeCLI:
+		status = "CLI process started (model will be downloaded if needed)...\n"
+	case spec.IsHF():
+		status = "Process started (model will be downloaded if needed)...\n"
+	case spec.Mode == process.ModeCLI:
+		status = "CLI process started - type your message and press Enter...\n"
+	}
+	m.instanceOutput[name] = header + status
+	m.focusInstance(name)
+	if spec.Mode == process.ModeCLI {
+		m.focusRight = true // Switch focus to right pane for interactive CLI
+		m.cliMode = true    // Enable CLI input mode
+		m.resizePTYs()
+	}
+	go m.readOutput(name)
+	return true
 }
 
-// startHFCli starts the llama-cli with a HuggingFace model
-func (m *Model) startHFCli(hfModel, quant string) {
-	m.output = fmt.Sprintf("Starting llama-cli for HF model %s:%s (NGL=%d, CtxSize=%d)...\n",
-		hfModel, quant, m.sessionNGL, m.sessionCtxSize)
+// stopInstance asks an instance to shut down in the background. The exit
+// reason arrives as a ProcessStoppedMsg once the grace period is over.
+func (m *Model) stopInstance(name string) tea.Cmd {
+	m.instanceOutput[name] += fmt.Sprintf("\n[Stopping %s...]\n", name)
+	return func() tea.Msg {
+		return ProcessStoppedMsg{Instance: name, Reason: m.processMgr.Stop(name)}
+	}
+}
 
-	if err := m.processMgr.StartCLIHF(hfModel, quant, m.sessionNGL, m.sessionCtxSize); err != nil {
-		m.output += "Error starting CLI: " + err.Error() + "\n"
-		if m.logger != nil {
-			m.logger.Error("Failed to start HF CLI", zap.Error(err))
-		}
-		return
+// focusInstance switches the output pane to the named instance ("" = main log)
+func (m *Model) focusInstance(name string) {
+	if m.focusedInstance != name {
+		m.cliMode = false
+		m.cliInputBuffer = ""
 	}
+	m.focusedInstance = name
+	m.scrollOffset = len(strings.Split(m.currentOutput(), "\n"))
+}
 
-	m.focusRight = true
-	m.cliMode = true
-	m.output += "CLI process started (model will be downloaded if needed)...\n"
-	go m.readOutput()
+// currentOutput returns the output buffer shown in the right pane
+func (m *Model) currentOutput() string {
+	if m.focusedInstance == "" {
+		return m.output
+	}
+	return m.instanceOutput[m.focusedInstance]
 }
 
-// readOutput reads from stdout and stderr pipes
-func (m *Model) readOutput() {
-	stdoutPipe, stderrPipe := m.processMgr.GetOutputPipes()
+// readOutput reads from stdout and stderr pipes of an instance
+func (m *Model) readOutput(name string) {
+	stdoutPipe, stderrPipe := m.processMgr.GetOutputPipes(name)
 	if stdoutPipe == nil {
 		return
 	}
 
 	// Read from both pipes in goroutines
-	go m.readPipe(stdoutPipe)
+	go m.readPipe(name, stdoutPipe)
 	if stderrPipe != nil {
-		go m.readPipe(stderrPipe)
+		go m.readPipe(name, stderrPipe)
 	}
 }
 
-// readPipe reads from a single pipe and sends output to the channel
-func (m *Model) readPipe(pipe *os.File) {
+// readPipe reads from a single pipe and sends output to the channel. When
+// the channel is full it waits for the next tick to drain it rather than
+// dropping output. This backpressure is deliberate: an instance writing
+// faster than the UI takes its output is slowed down by its pipe filling
+// up, just as with a terminal that cannot keep up.
+func (m *Model) readPipe(name string, pipe *os.File) {
 	buf := make([]byte, 1024)
 	for {
 		n, err := pipe.Read(buf)
 		if n > 0 {
-			output := string(buf[:n])
-			select {
-			case m.outputChan <- output:
-			default:
-				// Channel is full, drop the message
-			}
+			m.outputChan <- OutputMsg{Instance: name, Output: string(buf[:n])}
 		}
 		if err != nil {
 			if m.logger != nil {
@@ -0,0 +1,214 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+	"lloader/internal/models"
+)
+
+// newTestModel returns a Model for localModels whose files all live in a
+// temporary directory
+func newTestModel(t *testing.T, localModels []models.Model) *Model {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := app.DefaultConfig()
+	cfg.ModelsDir = dir
+	cfg.IndexFile = ""
+	cfg.UserDataFile = filepath.Join(dir, "userdata.yaml")
+	cfg.DownloadStateFile = filepath.Join(dir, "downloads.json")
+	cfg.HFCacheDir = filepath.Join(dir, "hf")
+	return NewModel(localModels, cfg, zap.NewNop())
+}
+
+func TestUpdate_CheckOutputIsBounded(t *testing.T) {
+	m := newTestModel(t, nil)
+	m.instanceOutput["chatty"] = ""
+
+	// Chatty instances keep the channel full
+	for range cap(m.outputChan) {
+		m.outputChan <- OutputMsg{Instance: "chatty", Output: "token "}
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	for range 4 {
+		go func() {
+			for {
+				select {
+				case m.outputChan <- OutputMsg{Instance: "chatty", Output: "token "}:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	// Let them block on the full channel, so each message taken is
+	// replaced at once
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		m.Update(CheckOutputMsg{})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Update did not return while the channel was flooded")
+	}
+	assert.NotEmpty(t, m.instanceOutput["chatty"])
+	assert.LessOrEqual(t, len(m.instanceOutput["chatty"]), len("token ")*cap(m.outputChan))
+}
+
+// manyModels returns n models named model-00.gguf and up, larger the
+// later they come by name
+func manyModels(n int) []models.Model {
+	list := make([]models.Model, n)
+	for i := range list {
+		name := fmt.Sprintf("model-%02d.gguf", i)
+		list[i] = models.Model{Name: name, Path: "/models/" + name, Size: int64(i+1) << 30}
+	}
+	return list
+}
+
+func press(m *Model, keys ...string) {
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "pgdown":
+			msg = tea.KeyMsg{Type: tea.KeyPgDown}
+		case "pgup":
+			msg = tea.KeyMsg{Type: tea.KeyPgUp}
+		case "home":
+			msg = tea.KeyMsg{Type: tea.KeyHome}
+		case "end":
+			msg = tea.KeyMsg{Type: tea.KeyEnd}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		m.Update(msg)
+		// Rendering scrolls the list and measures the page
+		m.View()
+	}
+}
+
+// assertSelectedVisible checks that the selected model is on screen
+func assertSelectedVisible(t *testing.T, m *Model) {
+	t.Helper()
+	view := m.View()
+	require.Positive(t, m.listPageSize)
+	assert.Less(t, m.listPageSize, len(m.models), "the list is taller than the pane")
+	assert.GreaterOrEqual(t, m.selected, m.listOffset)
+	assert.Less(t, m.selected, m.listOffset+m.listPageSize)
+	assert.GreaterOrEqual(t, m.listOffset, 0)
+	assert.LessOrEqual(t, m.listOffset, len(m.models)-m.listPageSize, "no empty rows below the last model")
+	assert.Contains(t, view, m.models[m.selected].Name)
+	assert.Contains(t, view, fmt.Sprintf("%d-%d of %d", m.listOffset+1, m.listOffset+m.listPageSize, len(m.models)))
+}
+
+func newLocalListModel(t *testing.T) *Model {
+	m := newTestModel(t, manyModels(50))
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 24})
+	m.View()
+	return m
+}
+
+func TestLocalList_Scrolls(t *testing.T) {
+	m := newLocalListModel(t)
+	assert.Equal(t, 0, m.listOffset)
+	assertSelectedVisible(t, m)
+
+	for range 30 {
+		press(m, "down")
+		assertSelectedVisible(t, m)
+	}
+	assert.Equal(t, 30, m.selected)
+
+	// Up wraps to the last model
+	press(m, "home", "up")
+	assert.Equal(t, 49, m.selected)
+	assertSelectedVisible(t, m)
+	press(m, "down")
+	assert.Equal(t, 0, m.selected)
+	assert.Equal(t, 0, m.listOffset)
+}
+
+func TestLocalList_Pages(t *testing.T) {
+	m := newLocalListModel(t)
+	page := m.listPageSize
+
+	press(m, "pgdown")
+	assert.Equal(t, page, m.selected)
+	assertSelectedVisible(t, m)
+
+	press(m, "end")
+	assert.Equal(t, 49, m.selected)
+	assert.Equal(t, 50-page, m.listOffset)
+	assertSelectedVisible(t, m)
+
+	press(m, "pgdown")
+	assert.Equal(t, 49, m.selected, "paging stops at the end")
+
+	press(m, "pgup")
+	assert.Equal(t, 49-page, m.selected)
+	assertSelectedVisible(t, m)
+
+	press(m, "home", "pgup")
+	assert.Equal(t, 0, m.selected, "paging stops at the top")
+	assert.Equal(t, 0, m.listOffset)
+}
+
+func TestLocalList_ResizeKeepsSelectionVisible(t *testing.T) {
+	m := newLocalListModel(t)
+	press(m, "end")
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 16})
+	assertSelectedVisible(t, m)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	assertSelectedVisible(t, m)
+}
+
+func TestLocalList_KeepsSelectionAcrossSortAndFilter(t *testing.T) {
+	m := newLocalListModel(t)
+	press(m, "pgdown", "pgdown", "down")
+	selected := m.models[m.selected].Path
+
+	// Size order puts the largest, last-named models first
+	press(m, "s")
+	assert.Equal(t, selected, m.models[m.selected].Path)
+	assertSelectedVisible(t, m)
+
+	// A filter that still matches keeps the model selected
+	press(m, "/", "model-")
+	require.True(t, m.localFilterFocused)
+	assert.Equal(t, selected, m.models[m.selected].Path)
+	press(m, "enter")
+	assertSelectedVisible(t, m)
+
+	// One that hides it selects the first match
+	press(m, "/", "4")
+	assert.Equal(t, "/models/model-49.gguf", m.models[m.selected].Path)
+	assert.Equal(t, 0, m.selected)
+	assert.Equal(t, 0, m.listOffset)
+
+	// Clearing the filter keeps what is selected now
+	press(m, "esc")
+	assert.Equal(t, "/models/model-49.gguf", m.models[m.selected].Path)
+	assert.Len(t, m.models, 50)
+	assertSelectedVisible(t, m)
+}
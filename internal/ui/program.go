@@ -4,25 +4,40 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"go.uber.org/zap"
 	"lloader/internal/app"
+	"lloader/internal/models"
 )
 
 type Program struct {
 	program *tea.Program
+	model   *Model
 	logger  *zap.Logger
 	config  *app.Config
 }
 
-func NewProgram(models []string, config *app.Config, logger *zap.Logger) *Program {
-	m := NewModel(models, config, logger)
+func NewProgram(localModels []models.Model, config *app.Config, logger *zap.Logger) *Program {
+	m := NewModel(localModels, config, logger)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
 	return &Program{
 		program: p,
+		model:   m,
 		logger:  logger,
 		config:  config,
 	}
 }
 
+// WatchModels replaces the Local list with every list received from
+// updates. It must be called before Run.
+func (p *Program) WatchModels(updates <-chan []models.Model) {
+	p.model.modelUpdates = updates
+}
+
+// Run runs the TUI until it quits, then stops the downloads, which
+// continue on the next start
 func (p *Program) Run() (tea.Model, error) {
-	return p.program.Run()
+	model, err := p.program.Run()
+	if closeErr := p.model.downloads.Close(); closeErr != nil {
+		p.logger.Warn("Failed to save the download queue", zap.Error(closeErr))
+	}
+	return model, err
 }
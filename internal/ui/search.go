@@ -0,0 +1,359 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+	"lloader/internal/app"
+	"lloader/internal/hf"
+)
+
+// hfPageSize is how many results each page of a search loads
+const hfPageSize = 20
+
+// pipelineTags are the tasks the search options cycle through, "" for any
+var pipelineTags = []string{"", "text-generation", "image-text-to-text", "feature-extraction", "sentence-similarity", "text-ranking"}
+
+// Fields of the search options modal
+const (
+	optSort = iota
+	optAuthor
+	optTask
+	optLicense
+	optCount
+)
+
+// startHFSearch searches the Hub for query with the saved filters, from
+// the first page
+func (m *Model) startHFSearch(query string) tea.Cmd {
+	filters := m.userData.HFSearch
+	m.hfOptions = hf.SearchOptions{
+		Search:      query,
+		Author:      filters.Author,
+		Sort:        filters.Sort,
+		PipelineTag: filters.PipelineTag,
+		License:     filters.License,
+		Limit:       hfPageSize,
+	}
+	m.hfSearching = true
+	m.hfLoadingMore = false
+	m.hfNext = ""
+	m.hfListOffset = 0
+	return m.searchHFPage(m.hfOptions, "")
+}
+
+// loadMoreHF fetches the next page of the current search, following the
+// cursor the Hub returned with the last page
+func (m *Model) loadMoreHF() tea.Cmd {
+	if m.hfNext == "" || m.hfSearching || m.hfLoadingMore {
+		return nil
+	}
+	m.hfLoadingMore = true
+	return m.searchHFPage(m.hfOptions, m.hfNext)
+}
+
+// searchHFPage loads one page of a search, the first if page is ""
+func (m *Model) searchHFPage(opts hf.SearchOptions, page string) tea.Cmd {
+	return func() tea.Msg {
+		result, info, err := m.hfCache.SearchPage(opts, page)
+		return HFSearchResultMsg{Options: opts, Page: page, Next: result.Next, Models: result.Models, Info: info, Err: err}
+	}
+}
+
+// setHFResults takes the results of a search or appends its next page.
+// Results of searches that were replaced since are dropped.
+func (m *Model) setHFResults(msg HFSearchResultMsg) {
+	if msg.Options != m.hfOptions || (msg.Page != "" && (!m.hfLoadingMore || msg.Page != m.hfNext)) {
+		return
+	}
+	more := msg.Page != ""
+	m.hfSearching = false
+	m.hfLoadingMore = false
+	if msg.Err != nil {
+		m.output += fmt.Sprintf("HF search error: %v\n", msg.Err)
+		m.alertAccess(msg.Err, "")
+		return
+	}
+
+	m.hfInfo = msg.Info
+	m.hfNext = msg.Next
+	if more {
+		m.hfResults = append(m.hfResults, msg.Models...)
+		m.applyHFView()
+		m.output += fmt.Sprintf("Loaded %d more models\n", len(msg.Models))
+	} else {
+		m.hfResults = msg.Models
+		m.hfSelected = 0
+		m.applyHFView()
+		m.output += fmt.Sprintf("Found %d models\n", len(m.hfModels))
+	}
+	m.reportStale(msg.Info)
+}
+
+// moveHFSelection moves the HF selection by delta rows. Moving past the
+// end wraps unless more results can be loaded; nearing the end loads them.
+func (m *Model) moveHFSelection(delta int) tea.Cmd {
+	n := len(m.hfModels)
+	if n == 0 {
+		return nil
+	}
+	switch next := m.hfSelected + delta; {
+	case next >= n && m.hfNext != "":
+		m.hfSelected = n - 1
+	case next >= n || next < 0:
+		m.hfSelected = (next%n + n) % n
+	default:
+		m.hfSelected = next
+	}
+	if m.hfSelected >= n-3 {
+		return m.loadMoreHF()
+	}
+	return nil
+}
+
+// renderHFList renders the search line, the search options and the
+// visible part of the results, scrolled so the selected repo is in view
+func (m *Model) renderHFList(width, height int, modelStyle, selectedStyle lipgloss.Style) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+	noteStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F1FA8C"))
+
+	var b strings.Builder
+	if m.hfSearchFocused {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Render("> "))
+		b.WriteString(m.hfSearchInput.View())
+	} else {
+		b.WriteString(dimStyle.Render("/ to search: "))
+		if m.hfSearchInput.Value() != "" {
+			b.WriteString(m.hfSearchInput.Value())
+		} else {
+			b.WriteString("...")
+		}
+	}
+	b.WriteString("\n")
+
+	// Search, options and stale lines, and the loading line below the list
+	rows := max(1, height-4)
+	if m.hfSelected < m.hfListOffset {
+		m.hfListOffset = m.hfSelected
+	}
+	if m.hfSelected >= m.hfListOffset+rows {
+		m.hfListOffset = m.hfSelected - rows + 1
+	}
+	m.hfListOffset = max(0, min(m.hfListOffset, len(m.hfModels)-rows))
+	end := min(len(m.hfModels), m.hfListOffset+rows)
+
+	position := filtersSummary(m.userData.HFSearch) + " (o)"
+	if len(m.hfModels) > 0 {
+		position += fmt.Sprintf(" · %d-%d of %d", m.hfListOffset+1, end, len(m.hfModels))
+		if m.hfNext != "" {
+			position += "+"
+		}
+	}
+	b.WriteString(dimStyle.Render(truncate(position, width-4)) + "\n")
+	if note := staleNote(m.hfInfo); note != "" {
+		b.WriteString(noteStyle.Render(note))
+	}
+	b.WriteString("\n")
+
+	if m.hfSearching {
+		b.WriteString(noteStyle.Render("Searching..."))
+		return b.String()
+	}
+	if len(m.hfModels) == 0 {
+		b.WriteString(dimStyle.Render("No results. Press / to search."))
+		return b.String()
+	}
+
+	labelWidth := width - 8 // padding, border and the selection marker
+	for i := m.hfListOffset; i < end; i++ {
+		model := m.hfModels[i]
+		label := truncate(favoriteMark(m.userData.Repo(model.ID), model.ID), labelWidth)
+		if i == m.hfSelected {
+			b.WriteString(selectedStyle.Render(" > " + label))
+		} else {
+			b.WriteString(modelStyle.Render("   " + label))
+		}
+		b.WriteString("\n")
+	}
+	if m.hfLoadingMore {
+		b.WriteString(noteStyle.Render("Loading more...") + "\n")
+	}
+	return b.String()
+}
+
+// filtersSummary renders the search options as "sort: likes · author:
+// unsloth"
+func filtersSummary(f app.SearchFilters) string {
+	parts := []string{"sort: " + hf.SortLabel(f.Sort)}
+	if f.Author != "" {
+		parts = append(parts, "author: "+f.Author)
+	}
+	if f.PipelineTag != "" {
+		parts = append(parts, "task: "+f.PipelineTag)
+	}
+	if f.License != "" {
+		parts = append(parts, "license: "+f.License)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// openSearchOptions edits the saved search filters
+func (m *Model) openSearchOptions() {
+	m.searchOptDraft = m.userData.HFSearch
+	m.authorInput.SetValue(m.searchOptDraft.Author)
+	m.licenseInput.SetValue(m.searchOptDraft.License)
+	m.focusSearchOption(optSort)
+	m.showSearchOptions = true
+}
+
+func (m *Model) closeSearchOptions() {
+	m.showSearchOptions = false
+	m.authorInput.Blur()
+	m.licenseInput.Blur()
+}
+
+// focusSearchOption moves the focus to field i, focusing its text input
+// if it has one
+func (m *Model) focusSearchOption(i int) {
+	m.searchOptFocus = i
+	m.authorInput.Blur()
+	m.licenseInput.Blur()
+	switch i {
+	case optAuthor:
+		m.authorInput.Focus()
+	case optLicense:
+		m.licenseInput.Focus()
+	}
+}
+
+// cycle returns the value after (or before, for delta -1) current in
+// values, starting over at the ends
+func cycle(values []string, current string, delta int) string {
+	i := slices.Index(values, current)
+	if i < 0 {
+		return values[0]
+	}
+	return values[(i+delta+len(values))%len(values)]
+}
+
+// updateSearchOptions handles keys in the search options modal. Enter
+// saves the filters and repeats the current search with them.
+func (m *Model) updateSearchOptions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch key := msg.String(); key {
+	case "esc":
+		m.closeSearchOptions()
+		return m, nil
+	case "enter":
+		m.searchOptDraft.Author = strings.TrimSpace(m.authorInput.Value())
+		m.searchOptDraft.License = strings.TrimSpace(m.licenseInput.Value())
+		m.closeSearchOptions()
+		if m.searchOptDraft == m.userData.HFSearch {
+			return m, nil
+		}
+		m.userData.HFSearch = m.searchOptDraft
+		if err := m.userData.Save(); err != nil {
+			m.output += fmt.Sprintf("Failed to save %s: %v\n", m.userData.Path(), err)
+			if m.logger != nil {
+				m.logger.Warn("Failed to save user data", zap.String("file", m.userData.Path()), zap.Error(err))
+			}
+		}
+		query, _ := app.SplitTagQuery(m.hfSearchInput.Value())
+		if query == "" && m.userData.HFSearch.Author == "" {
+			return m, nil
+		}
+		m.output += fmt.Sprintf("Searching HuggingFace with %s...\n", filtersSummary(m.userData.HFSearch))
+		return m, m.startHFSearch(query)
+	case "tab", "down":
+		m.focusSearchOption((m.searchOptFocus + 1) % optCount)
+		return m, nil
+	case "shift+tab", "up":
+		m.focusSearchOption((m.searchOptFocus + optCount - 1) % optCount)
+		return m, nil
+	case "left", "right", " ":
+		delta := 1
+		if key == "left" {
+			delta = -1
+		}
+		switch m.searchOptFocus {
+		case optSort:
+			m.searchOptDraft.Sort = cycle(hf.Sorts, orDefault(m.searchOptDraft.Sort, hf.SortDownloads), delta)
+			return m, nil
+		case optTask:
+			tags := pipelineTags
+			if !slices.Contains(tags, m.searchOptDraft.PipelineTag) {
+				tags = append([]string{m.searchOptDraft.PipelineTag}, tags...)
+			}
+			m.searchOptDraft.PipelineTag = cycle(tags, m.searchOptDraft.PipelineTag, delta)
+			return m, nil
+		}
+	}
+
+	switch m.searchOptFocus {
+	case optAuthor:
+		m.authorInput, cmd = m.authorInput.Update(msg)
+	case optLicense:
+		m.licenseInput, cmd = m.licenseInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// orDefault returns s, or fallback if s is ""
+func orDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// renderSearchOptions renders the search options modal
+func (m *Model) renderSearchOptions(base string, width, height int) string {
+	modalWidth := 60
+
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2"))
+	focusedLabel := lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
+
+	label := func(i int, text string) string {
+		if i == m.searchOptFocus {
+			return focusedLabel.Render("> " + text)
+		}
+		return labelStyle.Render("  " + text)
+	}
+	task := m.searchOptDraft.PipelineTag
+	if task == "" {
+		task = "any"
+	}
+
+	modalContent := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9")).Bold(true).Render("Search Options"),
+		"",
+		label(optSort, "Sort: ")+"‹ "+hf.SortLabel(m.searchOptDraft.Sort)+" ›",
+		"",
+		label(optAuthor, "Author:"),
+		"  "+m.authorInput.View(),
+		"",
+		label(optTask, "Task: ")+"‹ "+task+" ›",
+		"",
+		label(optLicense, "License:"),
+		"  "+m.licenseInput.View(),
+		"",
+		dimStyle.Render("Enter: Search | Esc: Cancel | ←/→: Change | Tab: Next"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Width(modalWidth).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#BD93F9")).
+		Background(lipgloss.Color("#282A36"))
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, modalStyle.Render(modalContent),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("#000000")))
+}